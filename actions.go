@@ -2,7 +2,8 @@ package argparse
 
 import (
 	"fmt"
-	"regexp"
+	"io/ioutil"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -19,9 +20,7 @@ func Store(p *Parser, f *Option, args ...string) ([]string, error) {
 		panic(fmt.Sprintf("option '%s' must expect at least one argument", f.DisplayName()))
 	} else if f.ArgNum == "?" {
 		if len(args) > 0 {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			p.Namespace.Set(f.DestName, args[0])
@@ -33,9 +32,7 @@ func Store(p *Parser, f *Option, args ...string) ([]string, error) {
 		}
 		var values []string
 		for len(args) > 0 {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			values = append(values, args[0])
@@ -44,7 +41,7 @@ func Store(p *Parser, f *Option, args ...string) ([]string, error) {
 
 		p.Namespace.Set(f.DestName, values)
 		return args, nil
-	} else if regexp.MustCompile(`^[1-9]+$`).MatchString(f.ArgNum) == true {
+	} else if argNumRegex.MatchString(f.ArgNum) == true {
 		num, _ := strconv.Atoi(f.ArgNum)
 		if len(args) < num {
 			return args, TooFewArgsErr{*f}
@@ -53,9 +50,7 @@ func Store(p *Parser, f *Option, args ...string) ([]string, error) {
 		if num > 1 {
 			var values []string
 			for _, v := range args[0:num] {
-				if err := ValidateChoice(*f, v); err != nil {
-					return args, err
-				} else if err := ValidateType(*f, v); err != nil {
+				if err := validateArg(p.Context(), *f, v); err != nil {
 					return args, err
 				}
 				values = append(values, v)
@@ -67,9 +62,7 @@ func Store(p *Parser, f *Option, args ...string) ([]string, error) {
 				args = args[num:]
 			}
 		} else {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			p.Namespace.Set(f.DestName, args[0])
@@ -116,11 +109,80 @@ func StoreTrue(p *Parser, f *Option, args ...string) ([]string, error) {
 	return args, nil
 }
 
+// StoreMap splits the option's argument on its first `=` into a key and value,
+// and stores the pair within the option's map in the parser. Later values for
+// a key replace earlier ones, and values may contain additional `=` characters.
+func StoreMap(p *Parser, f *Option, args ...string) ([]string, error) {
+	if f.ArgNum != "1" {
+		panic(fmt.Sprintf("option '%s' must expect exactly one argument.", f.DisplayName()))
+	}
+	if len(args) < 1 {
+		return args, TooFewArgsErr{*f}
+	}
+
+	pair := strings.SplitN(args[0], "=", 2)
+	if len(pair) != 2 || pair[0] == "" {
+		return args, InvalidKeyValueErr{*f, args[0]}
+	}
+	if err := validateArg(p.Context(), *f, args[0]); err != nil {
+		return args, err
+	}
+
+	mapping, ok := p.Namespace.Get(f.DestName).(map[string]string)
+	if ok == false {
+		mapping = make(map[string]string)
+	}
+	mapping[pair[0]] = pair[1]
+	p.Namespace.Set(f.DestName, mapping)
+
+	return args[1:], nil
+}
+
+// OpenFile returns an action which opens the file named by the option's argument
+// using the provided flags, such as os.O_RDONLY, and stores the *os.File into the
+// parser, retrieved using Namespace.File. An argument of `-` is stdin, or stdout
+// when the flags open the file for writing. Opened files are closed by the
+// parser's CloseFiles method.
+func OpenFile(flag int) Action {
+	return func(p *Parser, f *Option, args ...string) ([]string, error) {
+		if f.ArgNum != "1" {
+			panic(fmt.Sprintf("option '%s' must expect exactly one argument.", f.DisplayName()))
+		}
+		if len(args) < 1 {
+			return args, TooFewArgsErr{*f}
+		}
+		if err := validateArg(p.Context(), *f, args[0]); err != nil {
+			return args, err
+		}
+
+		if args[0] == "-" {
+			if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+				p.Namespace.Set(f.DestName, os.Stdout)
+			} else {
+				p.Namespace.Set(f.DestName, os.Stdin)
+			}
+			return args[1:], nil
+		}
+
+		file, err := os.OpenFile(args[0], flag, 0666)
+		if err != nil {
+			if pathErr, ok := err.(*os.PathError); ok == true {
+				err = pathErr.Err
+			}
+			return args, OpenFileErr{*f, args[0], err}
+		}
+		p.files = append(p.files, file)
+		p.Namespace.Set(f.DestName, file)
+
+		return args[1:], nil
+	}
+}
+
 // Append retrives the appropriate number of argumnents for the current option, (if any),
 // and appends them individually into the parser. Remaining arguments and errors are returned.
 func Append(p *Parser, f *Option, args ...string) ([]string, error) {
 	appendValue := func(p *Parser, f *Option, value interface{}) error {
-		if p.Namespace.KeyExists(f.DestName) == false {
+		if _, ok := p.Namespace.Get(f.DestName).([]string); ok == false {
 			p.Namespace.Set(f.DestName, make([]string, 0))
 		}
 		slice, err := p.Namespace.Try(f.DestName)
@@ -132,7 +194,7 @@ func Append(p *Parser, f *Option, args ...string) ([]string, error) {
 		return nil
 	}
 
-	if regexp.MustCompile(`^[1-9]+$`).MatchString(f.ArgNum) == true {
+	if argNumRegex.MatchString(f.ArgNum) == true {
 		num, _ := strconv.Atoi(f.ArgNum)
 		if len(args) < num {
 			return args, TooFewArgsErr{*f}
@@ -140,9 +202,7 @@ func Append(p *Parser, f *Option, args ...string) ([]string, error) {
 
 		count := 0
 		for count < num {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			appendValue(p, f, args[0])
@@ -155,9 +215,7 @@ func Append(p *Parser, f *Option, args ...string) ([]string, error) {
 		return args, nil
 	} else if f.ArgNum == "?" {
 		if len(args) > 0 {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			appendValue(p, f, args[0])
@@ -171,9 +229,7 @@ func Append(p *Parser, f *Option, args ...string) ([]string, error) {
 		}
 
 		for len(args) > 0 {
-			if err := ValidateChoice(*f, args[0]); err != nil {
-				return args, err
-			} else if err := ValidateType(*f, args[0]); err != nil {
+			if err := validateArg(p.Context(), *f, args[0]); err != nil {
 				return args, err
 			}
 			appendValue(p, f, args[0])
@@ -193,7 +249,7 @@ func AppendConst(p *Parser, f *Option, args ...string) ([]string, error) {
 		panic(fmt.Sprintf("option '%s' cannot expect any arguments.", f.DisplayName()))
 	}
 
-	if p.Namespace.KeyExists(f.DestName) == false {
+	if _, ok := p.Namespace.Get(f.DestName).([]string); ok == false {
 		p.Namespace.Set(f.DestName, make([]string, 0))
 	}
 	slice, err := p.Namespace.Try(f.DestName)
@@ -205,6 +261,112 @@ func AppendConst(p *Parser, f *Option, args ...string) ([]string, error) {
 	return args, nil
 }
 
+// AppendLines reads the file named by the option's argument and appends each of
+// its lines into the option's slice within the parser, such as for a list of
+// patterns. Leading and trailing whitespace is trimmed from each line, and blank
+// lines, as well as comments beginning with `#`, are skipped. An argument of `-`
+// reads stdin. Repeated options accumulate their lines within the same slice.
+func AppendLines(p *Parser, f *Option, args ...string) ([]string, error) {
+	if f.ArgNum != "1" {
+		panic(fmt.Sprintf("option '%s' must expect exactly one argument.", f.DisplayName()))
+	}
+	if len(args) < 1 {
+		return args, TooFewArgsErr{*f}
+	}
+
+	var content []byte
+	var err error
+	if args[0] == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok == true {
+			err = pathErr.Err
+		}
+		return args, OpenFileErr{*f, args[0], err}
+	}
+
+	slice, _ := p.Namespace.Get(f.DestName).([]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := validateArg(p.Context(), *f, line); err != nil {
+			return args, err
+		}
+		slice = append(slice, line)
+	}
+	if slice == nil {
+		slice = make([]string, 0)
+	}
+	p.Namespace.Set(f.DestName, slice)
+
+	return args[1:], nil
+}
+
+// AppendSplit returns an action which splits the option's argument on the
+// provided separator, such as `:` for `a:b:c`, and appends each segment into the
+// option's slice within the parser. Repeated options accumulate their segments
+// within the same slice. Empty segments, such as within `a::b` or `a:b:`, are
+// skipped.
+func AppendSplit(sep string) Action {
+	return func(p *Parser, f *Option, args ...string) ([]string, error) {
+		if f.ArgNum != "1" {
+			panic(fmt.Sprintf("option '%s' must expect exactly one argument.", f.DisplayName()))
+		}
+		if len(args) < 1 {
+			return args, TooFewArgsErr{*f}
+		}
+
+		slice, _ := p.Namespace.Get(f.DestName).([]string)
+		for _, segment := range strings.Split(args[0], sep) {
+			if segment == "" {
+				continue
+			}
+			if err := validateArg(p.Context(), *f, segment); err != nil {
+				return args, err
+			}
+			slice = append(slice, segment)
+		}
+		if slice == nil {
+			slice = make([]string, 0)
+		}
+		p.Namespace.Set(f.DestName, slice)
+
+		return args[1:], nil
+	}
+}
+
+// Callback returns an action which calls the provided function when the option
+// is encountered, without storing any value. Provided arguments remain unmodified.
+// When the function returns a StopErr, parsing stops successfully, such as for an
+// option which outputs information and exits; any other error is a parse error.
+func Callback(fn func() error) Action {
+	return func(p *Parser, f *Option, args ...string) ([]string, error) {
+		return args, fn()
+	}
+}
+
+// Count increments the number of times the option has been encountered, which
+// is stored into the parser. Provided arguments remain unmodified.
+func Count(p *Parser, f *Option, args ...string) ([]string, error) {
+	if f.ArgNum != "0" {
+		panic(fmt.Sprintf("option '%s' cannot expect any arguments.", f.DisplayName()))
+	}
+
+	count, _ := p.Namespace.Get(f.DestName).(string)
+	num, err := strconv.Atoi(count)
+	if err != nil {
+		num = 0
+	}
+	p.Namespace.Set(f.DestName, strconv.Itoa(num+1))
+
+	return args, nil
+}
+
 // ShowHelp calls the parser's ShowHelp function to output parser usage information
 // and help information for each option to stdout. Provided arguments remain unchanged.
 // It returns a ShowHelpErr error instance, used to prevent further parsing.
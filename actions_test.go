@@ -1,6 +1,13 @@
 package argparse
 
-import "testing"
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
 
 // TestStore_OneNargs tests the Store Action will store the expected value and
 // return the appropriate args & error when operating upon a option with
@@ -181,6 +188,30 @@ func TestStoreTrue(t *testing.T) {
 	}
 }
 
+// TestStoreMap tests the StoreMap Action will split KEY=VALUE arguments into the
+// option's map, with later keys overwriting earlier ones.
+func TestStoreMap(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOption(NewMap("D define", "define", "define a variable"))
+
+	ns, _, err := p.Parse("-D", "a=1", "--define", "b=x=y", "--define=a=2", "-Dempty=")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := map[string]string{"a": "2", "b": "x=y", "empty": ""}
+	if reflect.DeepEqual(ns.Map("define"), expected) == false {
+		t.Errorf("Expected map %v, but received: %v", expected, ns.Map("define"))
+	}
+
+	p = NewParser("parser")
+	p.AddOption(NewMap("define", "define", "define a variable"))
+	_, _, err = p.Parse("--define", "foo")
+	if expected := `--define: expected KEY=VALUE, got "foo"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
 // TestAppend_OneNargs tests the Append Action will store the expected value and
 // return the appropriate args & error when operating upon a option with
 // one expected argument.
@@ -296,3 +327,220 @@ func TestAppend_LeastOneNargs(t *testing.T) {
 		t.Error("An error was expected but not returned")
 	}
 }
+
+// TestCount tests the Count Action will increment the stored count each time it
+// is called, and return the provided args unmodified.
+func TestCount(t *testing.T) {
+	p := NewParser("parser")
+	f := NewOption("option", "option", "option").Nargs("0")
+	args := []string{"foobar"}
+
+	for i := 0; i < 3; i++ {
+		args, _ = Count(p, f, args...)
+	}
+
+	if len(args) != 1 {
+		t.Error("args should remain unmodified")
+	}
+
+	if p.Namespace.Mapping[f.DestName] != "3" {
+		t.Errorf("Action did not store correct count in parser: %v", p.Namespace.Mapping[f.DestName])
+	}
+}
+
+// TestCallback tests that a Callback action calls its function when the option
+// is encountered, and that a StopErr stops parsing successfully, even though a
+// required option was omitted, unless an earlier option was invalid.
+func TestCallback(t *testing.T) {
+	shown := false
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("version", "version", "show the version").Action(Callback(func() error {
+			shown = true
+			return StopErr{}
+		})),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).Required(),
+	)
+
+	ns, _, err := p.Parse("--version", "--output")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if shown == false || ns == nil {
+		t.Error("Expected the callback to be called, and parsing to stop successfully")
+	}
+
+	if _, _, err = p.Parse(); err == nil {
+		t.Error("An error was expected for the missing required option but did not occur")
+	}
+
+	// Errors occurring before the callback are still returned.
+	p.AddOption(NewOption("n count", "count", "count").Nargs("1").Action(Store).Type(reflect.Int))
+	expected := `-n, --count: invalid value "x": expected integer`
+	if _, _, err = p.Parse("--count", "x", "--version"); err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	failing := NewParser("parser")
+	failing.AddOption(NewOption("check", "check", "run a check").Action(Callback(func() error {
+		return errors.New("check failed")
+	})))
+	if _, _, err = failing.Parse("--check"); err == nil {
+		t.Error("An error was expected from the callback but did not occur")
+	}
+}
+
+// TestOpenFile tests that file options open the file named by their argument,
+// use stdin or stdout for `-`, and report files which cannot be opened. Opened
+// files are expected to be closed by CloseFiles.
+func TestOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "argparse")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFile("i input", "input", "input file", os.O_RDONLY),
+		NewFile("o output", "output", "output file", os.O_WRONLY|os.O_CREATE|os.O_TRUNC),
+	)
+
+	ns, _, err := p.Parse("-i", "-", "-o", "-")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.File("input") != os.Stdin || ns.File("output") != os.Stdout {
+		t.Errorf("Expected stdin and stdout for `-`, but received: %v", ns.Mapping)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	_, _, err = p.Parse("--input", missing)
+	if expected := `-i, --input: cannot open "` + missing + `": no such file or directory`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	output := filepath.Join(dir, "output.txt")
+	if ns, _, err = p.Parse("--output", output); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if _, err := ns.File("output").WriteString("hello"); err != nil {
+		t.Errorf("Expected the output file to be writable, but received: %s", err.Error())
+	}
+
+	if err := p.CloseFiles(); err != nil {
+		t.Errorf("An unexpected error occurred: %s", err.Error())
+	}
+	if _, err := ns.File("output").WriteString("world"); err == nil {
+		t.Error("Expected the output file to be closed by CloseFiles")
+	}
+	if content, _ := ioutil.ReadFile(output); string(content) != "hello" {
+		t.Errorf("Expected the output file to contain 'hello', but received: '%s'", content)
+	}
+}
+
+// TestAppend_Repeated tests that options using the Append and Count actions can
+// be repeated and interleaved with other options while parsing.
+func TestAppend_Repeated(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("I include", "include", "include path").Nargs("1").Action(Append),
+		NewOption("H header", "header", "header").Nargs("1").Action(Append),
+		NewCounter("v verbose", "verbose", "verbosity"),
+		NewFlag("d dry", "dry", "dry run"),
+	)
+
+	ns, _, err := p.Parse("-I", "one", "-vv", "--header", "a", "-d", "-Itwo", "--header=b", "-v")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	includes := ns.Slice("include")
+	if len(includes) != 2 || includes[0] != "one" || includes[1] != "two" {
+		t.Errorf("Expected includes '[one two]', but received: %v", includes)
+	}
+
+	headers := ns.Slice("header")
+	if len(headers) != 2 || headers[0] != "a" || headers[1] != "b" {
+		t.Errorf("Expected headers '[a b]', but received: %v", headers)
+	}
+
+	if verbose, _ := ns.Int("verbose"); verbose != 3 {
+		t.Errorf("Expected verbose 3, but received: %d", verbose)
+	}
+
+	if ns.String("dry") != "true" {
+		t.Errorf("Expected dry 'true', but received: '%s'", ns.String("dry"))
+	}
+}
+
+// TestAppendSplit tests the AppendSplit Action will split each argument on the
+// separator into the option's slice, accumulating segments across repeated
+// options and skipping empty segments.
+func TestAppendSplit(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"--path", "a:b:c"}, []string{"a", "b", "c"}},
+		{[]string{"--path", "a:b", "-p", "c", "--path=d:e"}, []string{"a", "b", "c", "d", "e"}},
+		{[]string{"--path", "a::b:"}, []string{"a", "b"}},
+		{[]string{"--path", ":"}, nil},
+	}
+
+	for _, test := range tests {
+		p := NewParser("parser")
+		p.AddOption(NewStringSlice("p path", "path", ":", "search path"))
+
+		ns, _, err := p.Parse(test.args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred: %s", err.Error())
+		}
+		if path := ns.Slice("path"); reflect.DeepEqual(path, test.expected) == false {
+			t.Errorf("Expected path %q for %q, but received: %q", test.expected, test.args, path)
+		}
+	}
+
+	p := NewParser("parser").Prog("prog")
+	p.AddOption(NewStringSlice("p path", "path", ":", "search path"))
+	if usage := p.GetUsage(); usage != "usage: prog [-h] [-p VALUE:...]" {
+		t.Errorf("Expected usage 'usage: prog [-h] [-p VALUE:...]', but received: '%s'", usage)
+	}
+}
+
+// TestAppendLines tests the AppendLines Action will append the lines of the file
+// named by each argument into the option's slice, skipping blank lines and
+// comments, and report files which cannot be read.
+func TestAppendLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "argparse")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	ioutil.WriteFile(first, []byte("# patterns to include\n*.go\n\n  docs/*.md  \n\t# indented comment\r\nREADME\r\n"), 0666)
+	ioutil.WriteFile(second, []byte("\n\nvendor/\n"), 0666)
+
+	p := NewParser("parser").Prog("prog")
+	p.AddOption(NewSliceFromFile("include-from", "include", "read patterns from a file"))
+
+	ns, _, err := p.Parse("--include-from", first, "--include-from="+second)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if expected := []string{"*.go", "docs/*.md", "README", "vendor/"}; reflect.DeepEqual(ns.Slice("include"), expected) == false {
+		t.Errorf("Expected include %q, but received: %q", expected, ns.Slice("include"))
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	_, _, err = p.Parse("--include-from", missing)
+	if expected := `--include-from: cannot open "` + missing + `": no such file or directory`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	if usage := p.GetUsage(); usage != "usage: prog [-h] [--include-from FILE]" {
+		t.Errorf("Expected usage 'usage: prog [-h] [--include-from FILE]', but received: '%s'", usage)
+	}
+}
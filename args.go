@@ -0,0 +1,144 @@
+package argparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParsedOption represents an option found while parsing program arguments,
+// along with the value bound to it, if any.
+type ParsedOption struct {
+	Name     string // Public name of the option, without its prefix.
+	Value    string // Value bound to the option.
+	HasValue bool   // Indicate if a value was bound to the option.
+}
+
+// ParsedArgs contains the options and arguments found within a slice of program
+// arguments, preserving the order in which they were provided and the
+// association between each option and its value.
+type ParsedArgs struct {
+	Options     []ParsedOption         // Options, in the order they were encountered.
+	Values      map[string]interface{} // Option names mapped to their string value, or `true` for flags.
+	Positionals []string               // Arguments which were not bound to an option.
+	Rest        []string               // Arguments following the `--` terminator, unmodified.
+	Terminator  int                    // Index of the `--` terminator within the arguments, or -1 if absent.
+}
+
+// ParseArgs parses the provided program arguments without requiring any options
+// to be defined. Without knowing which options expect a value, an option is bound
+// to the argument immediately following it, unless that argument is itself an
+// option. Values can be unambiguously bound using the `--option=value` syntax.
+// All arguments following a `--` are returned, unmodified, as the rest, along
+// with the index of the `--` itself.
+func ParseArgs(allArgs []string) (*ParsedArgs, error) {
+	parsed := &ParsedArgs{Values: make(map[string]interface{}), Terminator: -1}
+
+	for i, a := range allArgs {
+		if a == "--" {
+			parsed.Terminator = i
+			parsed.Rest = append([]string{}, allArgs[i+1:]...)
+			allArgs = allArgs[:i]
+			break
+		}
+	}
+
+	count := 0
+	max := len(allArgs)
+
+	for count < max {
+		a := allArgs[count]
+		count++
+
+		if strings.HasPrefix(a, "--=") {
+			return nil, InvalidOptionErr{name: a}
+		}
+
+		options, args := extractOptions(a)
+		if len(options) == 0 {
+			parsed.Positionals = append(parsed.Positionals, args...)
+			continue
+		}
+
+		last := &options[len(options)-1]
+		if last.hasValue == false && count < max {
+			if next, _ := extractOptions(allArgs[count]); len(next) == 0 {
+				last.value = allArgs[count]
+				last.hasValue = true
+				count++
+			}
+		}
+
+		for _, option := range options {
+			parsed.Options = append(parsed.Options, ParsedOption{option.name, option.value, option.hasValue})
+			if option.hasValue == true {
+				parsed.Values[option.name] = option.value
+			} else {
+				parsed.Values[option.name] = true
+			}
+		}
+	}
+
+	return parsed, nil
+}
+
+// SplitArgs splits a command line provided as a single string into arguments,
+// suitable for Parse, following the quoting rules of a shell. Arguments are
+// separated by whitespace, unless it is quoted or escaped. Single quotes group
+// text literally, while double quotes group text in which a backslash escapes
+// only `"` and `\`. Outside of quotes, a backslash escapes any character, such
+// as `a\ b` for the single argument `a b`. An error is returned for an
+// unterminated quote or a trailing backslash.
+func SplitArgs(line string) ([]string, error) {
+	return splitArgs(line, true)
+}
+
+// splitArgs splits the provided content into arguments on whitespace, grouping
+// quoted text and, when specified, interpreting backslashes as escapes, as
+// described by SplitArgs.
+func splitArgs(content string, escapes bool) ([]string, error) {
+	var args []string
+	var arg []rune
+	var quote rune
+	inArg := false
+	escaped := false
+
+	for _, r := range content {
+		switch {
+		case escaped == true:
+			if quote == '"' && r != '"' && r != '\\' {
+				arg = append(arg, '\\')
+			}
+			arg = append(arg, r)
+			escaped = false
+		case escapes == true && r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg = append(arg, r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg == true {
+				args = append(args, string(arg))
+				arg = nil
+				inArg = false
+			}
+		default:
+			arg = append(arg, r)
+			inArg = true
+		}
+	}
+
+	if escaped == true {
+		return nil, fmt.Errorf("missing character following a trailing backslash")
+	} else if quote != 0 {
+		return nil, fmt.Errorf("missing closing quote %c", quote)
+	}
+	if inArg == true {
+		args = append(args, string(arg))
+	}
+	return args, nil
+}
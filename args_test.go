@@ -0,0 +1,114 @@
+package argparse
+
+import "testing"
+
+// TestParseArgs tests to ensure that options are bound to the values following
+// them, in order, while other arguments are returned as positionals and all
+// arguments after `--` are returned unmodified.
+func TestParseArgs(t *testing.T) {
+	allArgs := []string{"--out", "x.txt", "extra", "-v", "--level=3", "--dry", "--", "-x", "--foo"}
+
+	parsed, err := ParseArgs(allArgs)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := []ParsedOption{
+		{"out", "x.txt", true},
+		{"v", "", false},
+		{"level", "3", true},
+		{"dry", "", false},
+	}
+	if len(parsed.Options) != len(expected) {
+		t.Fatalf("Expected options: '%v' but received: '%v'", expected, parsed.Options)
+	}
+	for i, option := range parsed.Options {
+		if option != expected[i] {
+			t.Errorf("Expected option: '%v' but received: '%v'", expected[i], option)
+		}
+	}
+
+	if parsed.Values["out"] != "x.txt" || parsed.Values["v"] != true {
+		t.Errorf("Unexpected option values: '%v'", parsed.Values)
+	}
+
+	if len(parsed.Positionals) != 1 || parsed.Positionals[0] != "extra" {
+		t.Errorf("Expected positionals: '[extra]' but received: '%v'", parsed.Positionals)
+	}
+
+	if len(parsed.Rest) != 2 || parsed.Rest[0] != "-x" || parsed.Rest[1] != "--foo" {
+		t.Errorf("Expected rest: '[-x --foo]' but received: '%v'", parsed.Rest)
+	}
+}
+
+// TestParseArgs_Terminator tests to ensure that the arguments before a `--` are
+// returned as positionals, distinct from the rest following it, and that the
+// index of the `--` is recorded.
+func TestParseArgs_Terminator(t *testing.T) {
+	parsed, err := ParseArgs([]string{"a", "--", "b", "c"})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if len(parsed.Positionals) != 1 || parsed.Positionals[0] != "a" {
+		t.Errorf("Expected positionals: '[a]' but received: '%v'", parsed.Positionals)
+	}
+
+	if len(parsed.Rest) != 2 || parsed.Rest[0] != "b" || parsed.Rest[1] != "c" {
+		t.Errorf("Expected rest: '[b c]' but received: '%v'", parsed.Rest)
+	}
+
+	if parsed.Terminator != 1 {
+		t.Errorf("Expected terminator index: '1' but received: '%d'", parsed.Terminator)
+	}
+
+	if parsed, _ = ParseArgs([]string{"a", "b"}); parsed.Terminator != -1 || parsed.Rest != nil {
+		t.Errorf("Expected no terminator or rest, but received: '%d' and '%v'", parsed.Terminator, parsed.Rest)
+	}
+}
+
+// TestParseArgs_InvalidOption tests to ensure that an option with an empty name
+// results in an error.
+func TestParseArgs_InvalidOption(t *testing.T) {
+	if _, err := ParseArgs([]string{"--=value"}); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+}
+
+// TestSplitArgs tests to ensure that a command line is split on whitespace, while
+// quoted and escaped whitespace is kept within a single argument.
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		`a "b c" d`:                {"a", "b c", "d"},
+		`a 'b c'`:                  {"a", "b c"},
+		`a\ b`:                     {"a b"},
+		`  --name="John Smith" -v`: {"--name=John Smith", "-v"},
+		`'it\s' "say \"hi\"\n"`:    {`it\s`, `say "hi"\n`},
+		`"" '' x`:                  {"", "", "x"},
+		`C:\\dir a\"b`:             {`C:\dir`, `a"b`},
+		"":                         nil,
+	}
+
+	for line, expected := range tests {
+		args, err := SplitArgs(line)
+		if err != nil {
+			t.Errorf("An unexpected error occurred for %q: %s", line, err.Error())
+			continue
+		}
+		if len(args) != len(expected) {
+			t.Errorf("Expected args %q for %q, but received: %q", expected, line, args)
+			continue
+		}
+		for i := range expected {
+			if args[i] != expected[i] {
+				t.Errorf("Expected arg %q at %d for %q, but received: %q", expected[i], i, line, args[i])
+			}
+		}
+	}
+
+	for _, line := range []string{`a "b c`, `a 'b`, `a\`} {
+		if _, err := SplitArgs(line); err == nil {
+			t.Errorf("Expected an error for %q, but received none", line)
+		}
+	}
+}
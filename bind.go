@@ -0,0 +1,185 @@
+package argparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationType is the type of time.Duration fields, which are distinguished
+// from other int64 fields.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// binding associates an option with the struct field its value is written to.
+type binding struct {
+	option *Option
+	field  reflect.Value
+}
+
+// Bind registers an option for each field of the struct pointed to by v which
+// has an `argparse` tag, and writes the parsed values back into those fields
+// once parsing succeeds. The tag contains comma-separated settings:
+//
+//	type Config struct {
+//		Verbose bool          `argparse:"short=v,long=verbose,help=Enable verbose output"`
+//		Port    int           `argparse:"short=p,required,help=Port to listen on"`
+//		Name    string        `argparse:"long=name,default=server"`
+//		Tags    []string      `argparse:"long=tag,help=Add a tag"`
+//		Timeout time.Duration `argparse:"long=timeout"`
+//	}
+//
+// The short and long settings are the option's public names, defaulting to the
+// lowercased field name. The default setting is the option's default value,
+// otherwise taken from the field's current value when it is not the zero value.
+// The required setting makes the option required. As the help setting takes the
+// remainder of the tag, it can contain commas, but must be the last setting.
+// Fields of type bool are flags, []string fields are appended to with each
+// occurrence of the option, and other fields of type int, string, or
+// time.Duration store the option's argument. Unexported fields, fields of any
+// other type, or tags containing unknown settings, return an error, as does a v
+// which is not a pointer to a struct. Fields tagged `argparse:"-"` are skipped.
+func (p *Parser) Bind(v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() == true || ptr.Elem().Kind() != reflect.Struct {
+		return InvalidBindErr{fmt.Sprintf("%T", v), "expected a pointer to a struct"}
+	}
+
+	var bindings []binding
+	value := ptr.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup("argparse")
+		if ok == false || tag == "-" {
+			continue
+		} else if field.PkgPath != "" {
+			return InvalidBindErr{field.Name, "field is unexported"}
+		}
+
+		option, err := bindOption(field, value.Field(i), tag)
+		if err != nil {
+			return err
+		}
+		bindings = append(bindings, binding{option, value.Field(i)})
+	}
+
+	for _, b := range bindings {
+		p.AddOption(b.option)
+	}
+	p.bindings = append(p.bindings, bindings...)
+	return nil
+}
+
+// bindOption returns a new option for the provided struct field, as configured
+// by its tag, or an error if the field cannot be bound.
+func bindOption(field reflect.StructField, value reflect.Value, tag string) (*Option, error) {
+	var names []string
+	var help, def string
+	required := false
+
+	for len(tag) > 0 {
+		setting := tag
+		if strings.HasPrefix(tag, "help=") == false {
+			if index := strings.Index(tag, ","); index >= 0 {
+				setting, tag = tag[:index], tag[index+1:]
+			} else {
+				tag = ""
+			}
+		} else {
+			tag = ""
+		}
+
+		key, val := setting, ""
+		if index := strings.Index(setting, "="); index >= 0 {
+			key, val = setting[:index], setting[index+1:]
+		}
+		switch key {
+		case "short", "long":
+			names = append(names, val)
+		case "help":
+			help = val
+		case "default":
+			def = val
+		case "required":
+			required = true
+		default:
+			return nil, InvalidBindErr{field.Name, fmt.Sprintf("unknown tag setting \"%s\"", key)}
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, strings.ToLower(field.Name))
+	}
+	if def == "" && value.IsZero() == false && value.Kind() != reflect.Slice {
+		def = fmt.Sprint(value.Interface())
+	}
+
+	var option *Option
+	switch {
+	case field.Type == durationType:
+		option = NewDuration(strings.Join(names, " "), field.Name, help)
+	case field.Type.Kind() == reflect.Bool:
+		option = NewFlag(strings.Join(names, " "), field.Name, help)
+	case field.Type.Kind() == reflect.Int:
+		option = NewOption(strings.Join(names, " "), field.Name, help).Nargs("1").Action(Store).Type(reflect.Int)
+	case field.Type.Kind() == reflect.String:
+		option = NewOption(strings.Join(names, " "), field.Name, help).Nargs("1").Action(Store)
+	case field.Type == reflect.TypeOf([]string{}):
+		option = NewOption(strings.Join(names, " "), field.Name, help).Nargs("1").Action(Append)
+	default:
+		return nil, InvalidBindErr{field.Name, fmt.Sprintf("unsupported type %s", field.Type)}
+	}
+
+	if len(def) > 0 {
+		option.Default(def)
+	}
+	if required == true {
+		option.Required()
+	}
+	return option, nil
+}
+
+// writeBindings writes the values of the parser's bound options into their
+// struct fields, along with those of a selected command, returning an error if
+// a value cannot be converted to its field's type. Options without a value
+// leave their fields unmodified.
+func (p *Parser) writeBindings() error {
+	for _, b := range p.bindings {
+		value := p.Namespace.Get(b.option.DestName)
+		if str, ok := value.(string); ok == true && str == "" {
+			continue
+		}
+
+		switch {
+		case b.field.Type() == durationType:
+			d, err := p.Namespace.Duration(b.option.DestName)
+			if err != nil {
+				return err
+			}
+			b.field.SetInt(int64(d))
+		case b.field.Kind() == reflect.Bool:
+			flag, err := parseBool(p.Namespace.String(b.option.DestName))
+			if err != nil {
+				return err
+			}
+			b.field.SetBool(flag)
+		case b.field.Kind() == reflect.Int:
+			i, err := strconv.Atoi(p.Namespace.String(b.option.DestName))
+			if err != nil {
+				return err
+			}
+			b.field.SetInt(int64(i))
+		case b.field.Kind() == reflect.String:
+			b.field.SetString(p.Namespace.String(b.option.DestName))
+		default:
+			b.field.Set(reflect.ValueOf(p.Namespace.Slice(b.option.DestName)))
+		}
+	}
+
+	if name, ok := p.Namespace.Get("command").(string); ok == true {
+		if command, err := p.getCommand(name); err == nil {
+			return command.writeBindings()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package argparse
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestParserBind tests the Bind method to ensure options are registered for the
+// tagged fields of a struct, and their parsed values are written to each type of
+// field, while untagged fields and fields without a value remain unmodified.
+func TestParserBind(t *testing.T) {
+	type config struct {
+		Verbose bool          `argparse:"short=v,long=verbose,help=Enable verbose output, for debugging"`
+		Color   bool          `argparse:"long=color"`
+		Port    int           `argparse:"short=p,required"`
+		Name    string        `argparse:"long=name,default=server"`
+		User    string        `argparse:"long=user"`
+		Tags    []string      `argparse:"long=tag"`
+		Timeout time.Duration `argparse:"long=timeout"`
+		Retries int           `argparse:"long=retries"`
+		Ignored string
+		Skipped int `argparse:"-"`
+	}
+
+	cfg := config{Color: true, Retries: 3, User: "nobody", Ignored: "kept"}
+	p := NewParser("parser")
+	if err := p.Bind(&cfg); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if option, err := p.GetOption("verbose"); err != nil || option.HelpText != "Enable verbose output, for debugging" {
+		t.Errorf("Expected a verbose option with help text, but received: %v (%v)", option, err)
+	}
+	if _, _, err := p.Parse(); err == nil {
+		t.Error("Expected an error for the missing required option, but received none")
+	}
+
+	_, _, err := p.Parse("-v", "--no-color", "-p", "8080", "--tag", "a", "--tag", "b", "--timeout", "1m30s")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := config{
+		Verbose: true,
+		Color:   false,
+		Port:    8080,
+		Name:    "server",
+		User:    "nobody",
+		Tags:    []string{"a", "b"},
+		Timeout: 90 * time.Second,
+		Retries: 3,
+		Ignored: "kept",
+	}
+	if reflect.DeepEqual(cfg, expected) == false {
+		t.Errorf("Expected config %+v, but received: %+v", expected, cfg)
+	}
+}
+
+// TestParserBind_Errors tests the Bind method to ensure values which are not
+// pointers to structs, unexported fields, fields of unsupported types, and
+// unknown tag settings return errors without registering any options.
+func TestParserBind_Errors(t *testing.T) {
+	var unsupported struct {
+		Name  string             `argparse:"long=name"`
+		Ratio map[string]float64 `argparse:"long=ratio"`
+	}
+	var unknown struct {
+		Name string `argparse:"long=name,hidden"`
+	}
+	var config struct{}
+	var unexported struct {
+		Name string `argparse:"long=name"`
+		port int    `argparse:"long=port"`
+	}
+	valued := struct {
+		name string `argparse:"long=name"`
+	}{name: "server"}
+
+	tests := map[string]interface{}{
+		"cannot bind struct {}: expected a pointer to a struct":  config,
+		"cannot bind Ratio: unsupported type map[string]float64": &unsupported,
+		"cannot bind Name: unknown tag setting \"hidden\"":       &unknown,
+		"cannot bind port: field is unexported":                  &unexported,
+		"cannot bind name: field is unexported":                  &valued,
+		"cannot bind *struct {}: expected a pointer to a struct": (*struct{})(nil),
+	}
+	for expected, v := range tests {
+		p := NewParser("parser")
+		if err := p.Bind(v); err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+		}
+		if len(p.Options) != 0 {
+			t.Errorf("Expected no options to be registered, but received: %d", len(p.Options))
+		}
+	}
+}
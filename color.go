@@ -0,0 +1,78 @@
+package argparse
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode determines whether the help text is colorized using ANSI escape
+// sequences, with option names in bold and headers in the header color.
+type ColorMode int
+
+const (
+	ColorNever  ColorMode = iota // Never colorize the help text.
+	ColorAuto                    // Colorize the help text when it is output to a terminal.
+	ColorAlways                  // Always colorize the help text.
+)
+
+// DefaultHeaderColor is the ANSI color of the headers within colorized help
+// text, as SGR parameters, when the parser does not set its own header color.
+var DefaultHeaderColor = "1;36"
+
+// SetColor sets whether the help text is colorized. By default, ColorNever keeps
+// the help text plain, while ColorAuto colorizes the help text only when it is
+// output to a terminal, and ColorAlways colorizes it wherever it is output.
+func (p *Parser) SetColor(mode ColorMode) *Parser {
+	p.Color = mode
+	return p
+}
+
+// SetHeaderColor sets the ANSI color of the headers within colorized help text,
+// as SGR parameters, such as "33" for yellow or "1;35" for bold magenta. An empty
+// color uses DefaultHeaderColor.
+func (p *Parser) SetHeaderColor(color string) *Parser {
+	p.HeaderColor = color
+	return p
+}
+
+// colorizes returns true if help text output to the provided writer is colorized
+// according to the parser's color mode, or otherwise false.
+func (p *Parser) colorizes(w io.Writer) bool {
+	switch p.Color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		f, ok := w.(*os.File)
+		return ok == true && isTerminal(f)
+	}
+	return false
+}
+
+// styleHeader returns the provided header in the parser's header color when the
+// help text is colorized, or otherwise unmodified.
+func (p *Parser) styleHeader(header string, color bool) string {
+	if color == false {
+		return header
+	}
+
+	headerColor := p.HeaderColor
+	if headerColor == "" {
+		headerColor = DefaultHeaderColor
+	}
+	return ansiStyle(header, headerColor)
+}
+
+// styleName returns the provided name in bold when the help text is colorized,
+// or otherwise unmodified.
+func styleName(name string, color bool) string {
+	if color == false {
+		return name
+	}
+	return ansiStyle(name, "1")
+}
+
+// ansiStyle returns the provided text wrapped within the ANSI escape sequences
+// applying, and then resetting, the provided SGR parameters.
+func ansiStyle(text, params string) string {
+	return join("", "\x1b[", params, "m", text, "\x1b[0m")
+}
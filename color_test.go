@@ -0,0 +1,87 @@
+package argparse
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// ansiRegex matches the ANSI escape sequences of colorized help text.
+var ansiRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// newColorParser returns a parser with options, a group, and a command, so that
+// its help text contains each kind of header.
+func newColorParser() *Parser {
+	output := NewOption("o output", "output", "the output file").Nargs("1").Action(Store)
+
+	p := NewParser("parser").Prog("prog").SetWidth(60)
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "Enable verbose output for every single operation"),
+		output,
+		NewOption("src", "src", "the source").Positional().Nargs("1").Action(Store),
+	)
+	p.Group("Output options", output)
+	p.AddCommand("run", "run the program")
+	return p
+}
+
+// TestParserSetColor_NotTerminal tests that the help text contains no ANSI
+// escape sequences when output to a writer which is not a terminal, unless the
+// help text is always colorized.
+func TestParserSetColor_NotTerminal(t *testing.T) {
+	for _, mode := range []ColorMode{ColorNever, ColorAuto} {
+		var buf bytes.Buffer
+		newColorParser().SetColor(mode).PrintHelp(&buf)
+		if strings.Contains(buf.String(), "\x1b") == true {
+			t.Errorf("Expected no ANSI escape sequences for mode %d, but received: %q", mode, buf.String())
+		}
+	}
+
+	readFile, writeFile, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer readFile.Close()
+	newColorParser().SetColor(ColorAuto).PrintHelp(writeFile)
+	writeFile.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(readFile)
+	if strings.Contains(buf.String(), "\x1b") == true {
+		t.Errorf("Expected no ANSI escape sequences when piped, but received: %q", buf.String())
+	}
+}
+
+// TestParserSetColor_Always tests that colorized help text has its option names
+// in bold and its headers in the header color, while remaining aligned exactly
+// as the plain help text once the escape sequences are removed.
+func TestParserSetColor_Always(t *testing.T) {
+	plain := newColorParser().GetHelp()
+
+	var buf bytes.Buffer
+	newColorParser().SetColor(ColorAlways).SetHeaderColor("33").PrintHelp(&buf)
+	colored := buf.String()
+
+	for _, expected := range []string{
+		"\x1b[1m-v, --[no-]verbose\x1b[0m",
+		"\x1b[1mrun\x1b[0m",
+		"\x1b[33mpositional arguments:\x1b[0m",
+		"\x1b[33mcommands:\x1b[0m",
+		"\x1b[33mOptions:\x1b[0m",
+		"\x1b[33mOutput options:\x1b[0m",
+	} {
+		if strings.Contains(colored, expected) == false {
+			t.Errorf("Expected the help text to contain %q, but received: %q", expected, colored)
+		}
+	}
+
+	if stripped := ansiRegex.ReplaceAllString(colored, ""); stripped != plain+"\n" {
+		t.Errorf("Expected the help text to align as plain text:\n%s\nbut received:\n%s", plain, stripped)
+	}
+
+	if help := newColorParser().SetColor(ColorAlways).GetHelp(); strings.Contains(help, "\x1b["+DefaultHeaderColor+"m") == false {
+		t.Errorf("Expected headers in the default header color, but received: %q", help)
+	}
+}
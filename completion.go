@@ -0,0 +1,409 @@
+package argparse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// CompleteFunc returns the candidates for completing an option's argument within
+// a shell, provided the portion of the argument already typed.
+type CompleteFunc func(prefix string) []string
+
+// completeCommand is the hidden command which the generated completion scripts
+// invoke the program with, to output the candidates of options completed by a
+// CompleteFunc.
+const completeCommand = "__complete"
+
+// completionOption contains the details of an option needed to complete it
+// within a shell.
+type completionOption struct {
+	long       []string // Long names of the option, without their prefix.
+	short      []string // Short names of the option, without their prefix.
+	help       string   // Help text describing the option.
+	choices    []string // Valid choices for the option's arguments.
+	takesValue bool     // Indicate if the option expects one or more arguments.
+	dynamic    bool     // Indicate if the option's arguments are completed by the program.
+}
+
+// completionSpec contains the options and commands of a parser needed to
+// generate shell completion scripts.
+type completionSpec struct {
+	name     string
+	help     string
+	options  []completionOption
+	commands []completionSpec
+}
+
+// getCompletionSpec collects the non-positional options and commands of the
+// parser, and of each of its commands, for generating shell completion scripts.
+func (p *Parser) getCompletionSpec() completionSpec {
+	p.addDefaultHelp()
+
+	spec := completionSpec{name: p.CommandName, help: p.UsageText}
+	for _, option := range p.Options {
+		if option.IsPositional == true || option.IsHidden == true {
+			continue
+		}
+
+		opt := completionOption{
+			help:       option.HelpText,
+			choices:    option.ValidChoices,
+			takesValue: option.ArgNum != "0" && strings.ContainsAny(option.ArgNum, "rR") == false,
+			dynamic:    option.Completer != nil,
+		}
+		for _, name := range option.PublicNames {
+			if option.isAlias(name) == true {
+				continue
+			} else if len(name) == 1 {
+				opt.short = append(opt.short, name)
+			} else if len(name) > 1 {
+				opt.long = append(opt.long, name)
+			}
+		}
+		spec.options = append(spec.options, opt)
+	}
+
+	for _, command := range p.Commands {
+		spec.commands = append(spec.commands, command.getCompletionSpec())
+	}
+	return spec
+}
+
+// names returns the prefixed short names of the option, followed by its prefixed
+// long names.
+func (opt completionOption) names() []string {
+	var names []string
+	for _, name := range opt.short {
+		names = append(names, "-"+name)
+	}
+	for _, name := range opt.long {
+		names = append(names, "--"+name)
+	}
+	return names
+}
+
+// optionWords returns the prefixed long names of the options, or their short
+// names for options without a long name.
+func (spec completionSpec) optionWords() []string {
+	var words []string
+	for _, opt := range spec.options {
+		if len(opt.long) > 0 {
+			for _, name := range opt.long {
+				words = append(words, "--"+name)
+			}
+		} else {
+			for _, name := range opt.short {
+				words = append(words, "-"+name)
+			}
+		}
+	}
+	return words
+}
+
+// commandNames returns the names of the spec's commands.
+func (spec completionSpec) commandNames() []string {
+	var names []string
+	for _, command := range spec.commands {
+		names = append(names, command.name)
+	}
+	return names
+}
+
+// complete outputs the candidates for completing the last of the provided
+// arguments, one per line, to the parser's output. The arguments preceeding it
+// select any command whose options are completed. The last argument is completed
+// as the value of the option preceeding it, or of the option attached to it, such
+// as `--branch=ma`, using the option's CompleteFunc, or otherwise its choices.
+func (p *Parser) complete(allArgs []string) {
+	if len(allArgs) == 0 {
+		return
+	}
+	words, current := allArgs[:len(allArgs)-1], allArgs[len(allArgs)-1]
+
+	parser := p
+	for {
+		parser.addDefaultHelp()
+		index := parser.commandIndex(words...)
+		if index < 0 {
+			break
+		}
+		command, err := parser.getCommand(words[index])
+		if err != nil {
+			return
+		}
+		parser, words = command, words[index+1:]
+	}
+
+	var option *Option
+	prefix, name := current, ""
+	if index := strings.IndexAny(current, parser.separators()); strings.HasPrefix(current, "--") && index >= 0 {
+		option, _ = parser.matchOption(current[2:index])
+		prefix, name = current[index+1:], current[:index+1]
+	} else if len(words) > 0 && strings.HasPrefix(words[len(words)-1], "-") {
+		option, _ = parser.matchOption(strings.TrimLeft(words[len(words)-1], "-"))
+	}
+	if option == nil || option.ArgNum == "0" || (name == "" && option.IsValueOptional == true) {
+		return
+	}
+
+	var candidates []string
+	if option.Completer != nil {
+		candidates = option.Completer(prefix)
+	} else {
+		for _, choice := range option.ValidChoices {
+			if strings.HasPrefix(choice, prefix) {
+				candidates = append(candidates, choice)
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		fmt.Fprintln(p.output(), name+candidate)
+	}
+}
+
+// completionFuncRegex matches characters which cannot be used within the name
+// of a shell function.
+var completionFuncRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// GenerateBashCompletion writes a bash completion script for the parser to the
+// provided writer, completing the long options, commands, and option choices
+// for the specified program name.
+func (p *Parser) GenerateBashCompletion(w io.Writer, progName string) error {
+	spec := p.getCompletionSpec()
+	funcName := "_" + completionFuncRegex.ReplaceAllString(progName, "_") + "_completion"
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# bash completion for %s\n", progName)
+	fmt.Fprintf(&b, "%s() {\n", funcName)
+	fmt.Fprintf(&b, "    local cur prev cmd word\n")
+	fmt.Fprintf(&b, "    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(&b, "    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+
+	if len(spec.commands) > 0 {
+		fmt.Fprintf(&b, "\n    for word in \"${COMP_WORDS[@]:1:COMP_CWORD-1}\"; do\n")
+		fmt.Fprintf(&b, "        case \"$word\" in\n")
+		fmt.Fprintf(&b, "            %s)\n", join("|", spec.commandNames()...))
+		fmt.Fprintf(&b, "                cmd=\"$word\"\n")
+		fmt.Fprintf(&b, "                break\n")
+		fmt.Fprintf(&b, "                ;;\n")
+		fmt.Fprintf(&b, "        esac\n")
+		fmt.Fprintf(&b, "    done\n")
+	}
+
+	fmt.Fprintf(&b, "\n    case \"$cmd\" in\n")
+	for _, command := range spec.commands {
+		writeBashCase(&b, command, command.name)
+	}
+	writeBashCase(&b, spec, "\"\"")
+	fmt.Fprintf(&b, "    esac\n")
+	fmt.Fprintf(&b, "}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", funcName, progName)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeBashCase writes the bash case completing the choices, options, and
+// commands of the provided spec, for the specified case pattern.
+func writeBashCase(b *bytes.Buffer, spec completionSpec, pattern string) {
+	fmt.Fprintf(b, "        %s)\n", pattern)
+
+	var choiceCases []string
+	for _, opt := range spec.options {
+		if (len(opt.choices) == 0 && opt.dynamic == false) || opt.takesValue == false {
+			continue
+		}
+
+		reply := fmt.Sprintf("compgen -W \"%s\" -- \"$cur\"", join(" ", opt.choices...))
+		if opt.dynamic == true {
+			reply = join(" ", "\"${COMP_WORDS[0]}\"", completeCommand, "\"${COMP_WORDS[@]:1:COMP_CWORD}\"")
+		}
+		choiceCases = append(choiceCases, fmt.Sprintf(
+			"                %s)\n"+
+				"                    COMPREPLY=($(%s))\n"+
+				"                    return 0\n"+
+				"                    ;;\n",
+			join("|", opt.names()...), reply,
+		))
+	}
+	if len(choiceCases) > 0 {
+		fmt.Fprintf(b, "            case \"$prev\" in\n")
+		fmt.Fprint(b, join("", choiceCases...))
+		fmt.Fprintf(b, "            esac\n")
+	}
+
+	words := spec.commandNames()
+	if len(words) > 0 {
+		fmt.Fprintf(b, "            if [[ \"$cur\" != -* ]]; then\n")
+		fmt.Fprintf(b, "                COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", join(" ", words...))
+		fmt.Fprintf(b, "                return 0\n")
+		fmt.Fprintf(b, "            fi\n")
+	}
+	fmt.Fprintf(b, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", join(" ", spec.optionWords()...))
+	fmt.Fprintf(b, "            ;;\n")
+}
+
+// GenerateZshCompletion writes a zsh completion script for the parser to the
+// provided writer, completing the options, commands, and option choices for the
+// specified program name, along with their descriptions.
+func (p *Parser) GenerateZshCompletion(w io.Writer, progName string) error {
+	spec := p.getCompletionSpec()
+	funcName := "_" + completionFuncRegex.ReplaceAllString(progName, "_")
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "#compdef %s\n\n", progName)
+	fmt.Fprintf(&b, "%s() {\n", funcName)
+	fmt.Fprintf(&b, "    local line state\n\n")
+	writeZshArguments(&b, spec, "    ", join(" ", progName, completeCommand))
+
+	if len(spec.commands) > 0 {
+		fmt.Fprintf(&b, "\n    case $state in\n")
+		fmt.Fprintf(&b, "        command)\n")
+		fmt.Fprintf(&b, "            local -a commands\n")
+		fmt.Fprintf(&b, "            commands=(\n")
+		for _, command := range spec.commands {
+			fmt.Fprintf(&b, "                %s\n", zshQuote(strings.Replace(command.name, ":", "\\:", -1)+":"+command.help))
+		}
+		fmt.Fprintf(&b, "            )\n")
+		fmt.Fprintf(&b, "            _describe 'command' commands\n")
+		fmt.Fprintf(&b, "            ;;\n")
+		fmt.Fprintf(&b, "        args)\n")
+		fmt.Fprintf(&b, "            case $line[1] in\n")
+		for _, command := range spec.commands {
+			fmt.Fprintf(&b, "                %s)\n", command.name)
+			writeZshArguments(&b, command, "                    ", join(" ", progName, completeCommand, command.name))
+			fmt.Fprintf(&b, "                    ;;\n")
+		}
+		fmt.Fprintf(&b, "            esac\n")
+		fmt.Fprintf(&b, "            ;;\n")
+		fmt.Fprintf(&b, "    esac\n")
+	}
+
+	fmt.Fprintf(&b, "}\n\n")
+	fmt.Fprintf(&b, "%s \"$@\"\n", funcName)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeZshArguments writes a call to `_arguments` describing the options of the
+// provided spec, and its commands if it has any, at the specified indentation.
+// Options completed by the program run the provided complete command, followed
+// by the words of the command line.
+func writeZshArguments(b *bytes.Buffer, spec completionSpec, indent, complete string) {
+	escaper := strings.NewReplacer("[", "\\[", "]", "\\]")
+
+	var specs []string
+	for _, opt := range spec.options {
+		names := opt.names()
+		description := "[" + escaper.Replace(opt.help) + "]"
+
+		value := ""
+		if opt.takesValue == true {
+			value = ":value:"
+			if opt.dynamic == true {
+				value = value + "{compadd -- ${(f)\"$(" + complete + " \"${(@)words[2,CURRENT]}\")\"}}"
+			} else if len(opt.choices) > 0 {
+				value = value + "(" + join(" ", opt.choices...) + ")"
+			}
+			for i, name := range names {
+				if strings.HasPrefix(name, "--") {
+					names[i] = name + "="
+				}
+			}
+		}
+
+		if len(names) == 1 {
+			specs = append(specs, zshQuote(names[0]+description+value))
+		} else {
+			exclusions := strings.Replace(join(" ", names...), "=", "", -1)
+			specs = append(specs, zshQuote("("+exclusions+")")+"{"+join(",", names...)+"}"+zshQuote(description+value))
+		}
+	}
+
+	arguments := indent + "_arguments"
+	if len(spec.commands) > 0 {
+		arguments = arguments + " -C"
+		specs = append(specs, zshQuote("1: :->command"), zshQuote("*:: :->args"))
+	}
+
+	fmt.Fprintf(b, "%s \\\n", arguments)
+	for i, optSpec := range specs {
+		if i < len(specs)-1 {
+			fmt.Fprintf(b, "%s    %s \\\n", indent, optSpec)
+		} else {
+			fmt.Fprintf(b, "%s    %s\n", indent, optSpec)
+		}
+	}
+}
+
+// zshQuote returns the provided text within single quotes, escaping any single
+// quotes it contains.
+func zshQuote(text string) string {
+	return "'" + strings.Replace(text, "'", "'\\''", -1) + "'"
+}
+
+// GenerateFishCompletion writes a fish completion script for the parser to the
+// provided writer, completing the options, commands, and option choices for the
+// specified program name, along with their descriptions.
+func (p *Parser) GenerateFishCompletion(w io.Writer, progName string) error {
+	spec := p.getCompletionSpec()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# fish completion for %s\n", progName)
+
+	condition := ""
+	if len(spec.commands) > 0 {
+		condition = "__fish_use_subcommand"
+		for _, command := range spec.commands {
+			fmt.Fprintf(&b, "complete -c %s -n %s -f -a %s -d %s\n", progName, fishQuote(condition), fishQuote(command.name), fishQuote(command.help))
+		}
+	}
+	writeFishOptions(&b, spec, progName, condition)
+
+	for _, command := range spec.commands {
+		writeFishOptions(&b, command, progName, "__fish_seen_subcommand_from "+command.name)
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeFishOptions writes a `complete` command for each option of the provided
+// spec, limited to the specified condition if it is not empty.
+func writeFishOptions(b *bytes.Buffer, spec completionSpec, progName, condition string) {
+	for _, opt := range spec.options {
+		line := []string{"complete", "-c", progName}
+		if condition != "" {
+			line = append(line, "-n", fishQuote(condition))
+		}
+		for _, name := range opt.short {
+			line = append(line, "-s", name)
+		}
+		for _, name := range opt.long {
+			line = append(line, "-l", name)
+		}
+		if len(opt.help) > 0 {
+			line = append(line, "-d", fishQuote(opt.help))
+		}
+		if opt.takesValue == true {
+			line = append(line, "-r")
+			if opt.dynamic == true {
+				complete := join(" ", progName, completeCommand, "(commandline -opc | tail -n +2)", "(commandline -ct)")
+				line = append(line, "-f", "-a", fishQuote("("+complete+")"))
+			} else if len(opt.choices) > 0 {
+				line = append(line, "-f", "-a", fishQuote(join(" ", opt.choices...)))
+			}
+		}
+		fmt.Fprintln(b, join(" ", line...))
+	}
+}
+
+// fishQuote returns the provided text within single quotes, escaping any
+// backslashes and single quotes it contains.
+func fishQuote(text string) string {
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "\\'").Replace(text) + "'"
+}
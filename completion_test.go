@@ -0,0 +1,137 @@
+package argparse
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newCompletionParser returns a parser with a variety of options and commands
+// for testing the generated shell completion scripts.
+func newCompletionParser() *Parser {
+	p := NewParser("Manage a project").Prog("proj")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "Enable verbose output"),
+		NewOption("color", "color", "When to use color").Nargs("1").Action(Store).Choices("auto", "always", "never"),
+		NewOption("o", "output", "Output file").Nargs("1").Action(Store),
+		NewArg("target", "target", "Target to operate on"),
+	)
+
+	build := p.AddCommand("build", "Build the project")
+	build.AddOptions(
+		NewFlag("release", "release", "Build in release mode"),
+		NewOption("arch", "arch", "Target architecture").Nargs("1").Action(Store).Choices("amd64", "arm64"),
+	)
+	p.AddCommand("clean", "Remove build artifacts")
+
+	return p
+}
+
+// checkGolden compares the provided output against the golden file of the
+// specified name within the testdata directory.
+func checkGolden(t *testing.T, name string, actual []byte) {
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if bytes.Equal(actual, expected) == false {
+		t.Errorf("Output does not match %s; expected:\n%s\nbut received:\n%s", name, expected, actual)
+	}
+}
+
+// TestParserGenerateBashCompletion tests that the generated bash completion
+// script matches the expected golden file.
+func TestParserGenerateBashCompletion(t *testing.T) {
+	var b bytes.Buffer
+	if err := newCompletionParser().GenerateBashCompletion(&b, "proj"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	checkGolden(t, "completion.bash", b.Bytes())
+}
+
+// TestParserGenerateZshCompletion tests that the generated zsh completion
+// script matches the expected golden file.
+func TestParserGenerateZshCompletion(t *testing.T) {
+	var b bytes.Buffer
+	if err := newCompletionParser().GenerateZshCompletion(&b, "proj"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	checkGolden(t, "completion.zsh", b.Bytes())
+}
+
+// TestParserGenerateFishCompletion tests that the generated fish completion
+// script matches the expected golden file.
+func TestParserGenerateFishCompletion(t *testing.T) {
+	var b bytes.Buffer
+	if err := newCompletionParser().GenerateFishCompletion(&b, "proj"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	checkGolden(t, "completion.fish", b.Bytes())
+}
+
+// TestParserComplete tests that the hidden complete command outputs the
+// candidates returned by the CompleteFunc of the option being completed, within
+// a selected command, for both separate and attached values, and otherwise the
+// option's matching choices.
+func TestParserComplete(t *testing.T) {
+	var prefixes []string
+	branches := func(prefix string) []string {
+		prefixes = append(prefixes, prefix)
+		var candidates []string
+		for _, branch := range []string{"main", "feature-a", "feature-b"} {
+			if strings.HasPrefix(branch, prefix) {
+				candidates = append(candidates, branch)
+			}
+		}
+		return candidates
+	}
+
+	p := newCompletionParser()
+	checkout := p.AddCommand("checkout", "Switch branches")
+	checkout.AddOptions(
+		NewOption("b branch", "branch", "Branch to switch to").Nargs("1").Action(Store).CompleteWith(branches),
+		NewFlag("f force", "force", "Discard local changes"),
+	)
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"checkout", "--branch", "fea"}, "feature-a\nfeature-b\n"},
+		{[]string{"-v", "checkout", "-f", "-b", ""}, "main\nfeature-a\nfeature-b\n"},
+		{[]string{"checkout", "--branch=m"}, "--branch=main\n"},
+		{[]string{"--color", "a"}, "auto\nalways\n"},
+		{[]string{"checkout", "-f", ""}, ""},
+		{[]string{"--branch", ""}, ""},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		p.SetOutput(&b)
+		args := append([]string{"__complete"}, test.args...)
+		if _, _, err := p.Parse(args...); err != (ShowCompletionErr{}) {
+			t.Errorf("Expected a ShowCompletionErr for %q, but received: '%v'", test.args, err)
+		}
+		if b.String() != test.expected {
+			t.Errorf("Expected candidates %q for %q, but received: %q", test.expected, test.args, b.String())
+		}
+	}
+
+	if expected := []string{"fea", "", "m"}; reflect.DeepEqual(prefixes, expected) == false {
+		t.Errorf("Expected the hook to receive prefixes %q, but received: %q", expected, prefixes)
+	}
+
+	var b bytes.Buffer
+	if err := p.GenerateBashCompletion(&b, "proj"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if expected := "COMPREPLY=($(\"${COMP_WORDS[0]}\" __complete \"${COMP_WORDS[@]:1:COMP_CWORD}\"))"; strings.Contains(b.String(), expected) == false {
+		t.Errorf("Expected the bash completion script to contain '%s', but received:\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,111 @@
+package argparse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// LoadConfig reads default values for the parser's options from the JSON file
+// at the provided path. Each key of the file's object is the long name of an
+// option, and its value is a string, number, or boolean, or an array of those
+// for options expecting multiple arguments. When parsing, an option absent from
+// the command line takes its value from its environment variable, then from the
+// config file, and then from its default value. The config file also applies to
+// the parser's commands.
+func (p *Parser) LoadConfig(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ConfigFileErr{path, err}
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return ConfigFileErr{path, err}
+	}
+
+	config := make(map[string]interface{})
+	for key, value := range raw {
+		if value == nil {
+			continue
+		}
+
+		if values, ok := value.([]interface{}); ok == true {
+			var slice []string
+			for _, v := range values {
+				str, ok := configString(v)
+				if ok == false {
+					return ConfigFileErr{path, fmt.Errorf("key \"%s\" contains an unsupported value: %v", key, v)}
+				}
+				slice = append(slice, str)
+			}
+			config[key] = slice
+		} else if str, ok := configString(value); ok == true {
+			config[key] = str
+		} else {
+			return ConfigFileErr{path, fmt.Errorf("key \"%s\" contains an unsupported value: %v", key, value)}
+		}
+	}
+
+	p.setConfig(config)
+	return nil
+}
+
+// setConfig sets the config values used by the parser, and each of its commands.
+func (p *Parser) setConfig(config map[string]interface{}) {
+	p.config = config
+	for _, command := range p.Commands {
+		command.setConfig(config)
+	}
+}
+
+// getConfigValue returns the config key and value for the provided option, found
+// using the option's long names, and true, or otherwise false when there is no
+// value.
+func (p *Parser) getConfigValue(option *Option) (string, interface{}, bool) {
+	for _, name := range option.PublicNames {
+		if len(name) <= 1 {
+			continue
+		}
+		if value, ok := p.config[name]; ok == true {
+			return name, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// configString returns the string representation of a JSON string, number, or
+// boolean value, and true, or otherwise false for any other value.
+func configString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// validateConfigValue validates the config value, or each of the config values,
+// for the provided option, naming the config key within any error returned. The
+// context is provided to validators added by ValidateContext.
+func validateConfigValue(ctx context.Context, f Option, key string, value interface{}) error {
+	values, ok := value.([]string)
+	if ok == false {
+		values = []string{value.(string)}
+	}
+
+	for _, v := range values {
+		if err := validateArg(ctx, f, v); err != nil {
+			return ConfigValueErr{key, err}
+		}
+	}
+	return nil
+}
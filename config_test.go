@@ -0,0 +1,118 @@
+package argparse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes the provided content to a config file within a temporary
+// directory, returning the file's path and a function removing the directory.
+func writeConfig(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "argparse")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", err)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Unable to write the config file: %v", err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// TestParserLoadConfig tests the LoadConfig method to ensure an option's value is
+// taken from the command line, then its environment variable, then the config
+// file, and then its default value.
+func TestParserLoadConfig(t *testing.T) {
+	oldHost, hadHost := os.LookupEnv("ARGPARSE_TEST_HOST")
+	defer func() {
+		if hadHost {
+			os.Setenv("ARGPARSE_TEST_HOST", oldHost)
+		} else {
+			os.Unsetenv("ARGPARSE_TEST_HOST")
+		}
+	}()
+
+	path, cleanup := writeConfig(t, `{"host": "config-host", "port": 8080, "verbose": true, "tags": ["a", "b"], "user": null}`)
+	defer cleanup()
+
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("host", "host", "server host").Nargs("1").Action(Store).Default("localhost").FromEnv("ARGPARSE_TEST_HOST"),
+		NewOption("p port", "port", "server port").Nargs("1").Action(Store).Default("80").Type(reflect.Int),
+		NewOption("v verbose", "verbose", "verbose output"),
+		NewOption("tags", "tags", "server tags").Nargs("+").Action(Store),
+		NewOption("user", "user", "server user").Nargs("1").Action(Store).Default("nobody"),
+		NewOption("token", "token", "api token").Nargs("1").Action(Store).Required(),
+	)
+	if err := p.LoadConfig(path); err != nil {
+		t.Fatalf("Expected config file to load, but received error: %v", err)
+	}
+
+	os.Setenv("ARGPARSE_TEST_HOST", "env-host")
+	ns, _, err := p.Parse("--host", "cli-host", "--token", "t")
+	if err != nil || ns.String("host") != "cli-host" {
+		t.Errorf("Expected host 'cli-host', but received: '%s' (%v)", ns.String("host"), err)
+	}
+
+	ns, _, err = p.Parse("--token", "t")
+	if err != nil || ns.String("host") != "env-host" {
+		t.Errorf("Expected host 'env-host', but received: '%s' (%v)", ns.String("host"), err)
+	}
+
+	os.Unsetenv("ARGPARSE_TEST_HOST")
+	ns, _, err = p.Parse("--token", "t")
+	if err != nil || ns.String("host") != "config-host" {
+		t.Errorf("Expected host 'config-host', but received: '%s' (%v)", ns.String("host"), err)
+	}
+	if port, err := ns.Int("port"); err != nil || port != 8080 {
+		t.Errorf("Expected port 8080, but received: %d (%v)", port, err)
+	}
+	if ns.String("verbose") != "true" {
+		t.Errorf("Expected verbose 'true', but received: '%s'", ns.String("verbose"))
+	}
+	if tags := ns.Slice("tags"); reflect.DeepEqual(tags, []string{"a", "b"}) == false {
+		t.Errorf("Expected tags [a b], but received: %v", tags)
+	}
+	if ns.String("user") != "nobody" {
+		t.Errorf("Expected user 'nobody', but received: '%s'", ns.String("user"))
+	}
+
+	if _, _, err := p.Parse(); err == nil {
+		t.Error("Expected an error for the missing required option, but received none")
+	}
+}
+
+// TestParserLoadConfig_Errors tests the LoadConfig method to ensure invalid config
+// files, and config values which are invalid for their option, produce errors
+// naming the file or key.
+func TestParserLoadConfig_Errors(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOption(NewOption("p port", "port", "server port").Nargs("1").Action(Store).Type(reflect.Int))
+
+	if err := p.LoadConfig(filepath.Join(os.TempDir(), "argparse-missing.json")); err == nil {
+		t.Error("Expected an error for a missing config file, but received none")
+	}
+
+	path, cleanup := writeConfig(t, `{"port": {"number": 80}}`)
+	defer cleanup()
+	if err := p.LoadConfig(path); err == nil || strings.Contains(err.Error(), `key "port"`) == false {
+		t.Errorf("Expected an error naming the key, but received: %v", err)
+	}
+
+	path, cleanup = writeConfig(t, `{"port": "eighty"}`)
+	defer cleanup()
+	if err := p.LoadConfig(path); err != nil {
+		t.Fatalf("Expected config file to load, but received error: %v", err)
+	}
+
+	_, _, err := p.Parse()
+	if _, ok := err.(ConfigValueErr); ok == false || strings.Contains(err.Error(), `config key "port"`) == false {
+		t.Errorf("Expected a ConfigValueErr naming the key, but received: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package argparse
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// optionDescription contains the details of a non-positional option, as output
+// by DescribeJSON.
+type optionDescription struct {
+	Short    []string `json:"short"`    // Short names of the option, without their prefix.
+	Long     []string `json:"long"`     // Long names of the option, without their prefix.
+	Dest     string   `json:"dest"`     // Key of the option's value within the namespace.
+	Type     string   `json:"type"`     // Type of the option's value.
+	Nargs    string   `json:"nargs"`    // Number of arguments the option expects.
+	Default  string   `json:"default"`  // Default value of the option.
+	Required bool     `json:"required"` // Indicate if the option must be present.
+	Choices  []string `json:"choices"`  // Valid choices for the option's arguments.
+	Help     string   `json:"help"`     // Help text describing the option.
+	MetaVar  string   `json:"metavar"`  // Usage of the option's arguments, such as `FILE`.
+}
+
+// positionalDescription contains the details of a positional option, as output
+// by DescribeJSON.
+type positionalDescription struct {
+	Name     string   `json:"name"`     // Name of the positional option.
+	Dest     string   `json:"dest"`     // Key of the option's value within the namespace.
+	Type     string   `json:"type"`     // Type of the option's value.
+	Nargs    string   `json:"nargs"`    // Number of arguments the option binds.
+	Default  string   `json:"default"`  // Default value of the option.
+	Required bool     `json:"required"` // Indicate if the option must be bound.
+	Choices  []string `json:"choices"`  // Valid choices for the option's arguments.
+	Help     string   `json:"help"`     // Help text describing the option.
+	MetaVar  string   `json:"metavar"`  // Usage of the option, such as `<file>`.
+}
+
+// parserDescription contains the details of a parser, or one of its commands,
+// as output by DescribeJSON.
+type parserDescription struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Usage       string                  `json:"usage"`
+	Options     []optionDescription     `json:"options"`
+	Positionals []positionalDescription `json:"positionals"`
+	Commands    []parserDescription     `json:"commands"`
+}
+
+// DescribeJSON writes a JSON document describing the parser to the provided
+// writer, as the structured counterpart to its help text, such as for tools
+// generating documentation or graphical interfaces. The document has the
+// following fields, each of which is always present:
+//
+//	name         The program name, or the command name for commands.
+//	description  The parser's description.
+//	usage        The usage line, without its `usage:` prefix.
+//	options      The non-positional options, each with the fields `short` and
+//	             `long` listing its names without their prefixes, `dest`, `type`,
+//	             `nargs`, `default`, `required`, `choices`, `help`, and `metavar`.
+//	positionals  The positional options, each with the fields `name`, `dest`,
+//	             `type`, `nargs`, `default`, `required`, `choices`, `help`, and
+//	             `metavar`.
+//	commands     The parser's commands, each described by the same fields.
+//
+// An option's type is one of `bool` for flags, `count` for counters, `none` for
+// other options which expect no arguments, `duration`, the kind set by Type,
+// such as `int`, or otherwise `string`. Options and commands are listed in the
+// order they were added, omitting hidden options and the aliases of options.
+func (p *Parser) DescribeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p.describe(p.ProgramName))
+}
+
+// describe returns the description of the parser, and of each of its commands,
+// for DescribeJSON.
+func (p *Parser) describe(name string) parserDescription {
+	p.addDefaultHelp()
+
+	description := parserDescription{
+		Name:        name,
+		Description: p.UsageText,
+		Usage:       strings.TrimPrefix(p.GetUsage(), "usage: "),
+		Options:     []optionDescription{},
+		Positionals: []positionalDescription{},
+		Commands:    []parserDescription{},
+	}
+
+	for _, option := range p.Options {
+		if option.IsHidden == true {
+			continue
+		}
+
+		choices := append([]string{}, option.ValidChoices...)
+		if option.IsPositional == true {
+			description.Positionals = append(description.Positionals, positionalDescription{
+				Name:     option.PublicNames[0],
+				Dest:     option.DestName,
+				Type:     option.getTypeName(),
+				Nargs:    option.ArgNum,
+				Default:  option.DefaultVal,
+				Required: option.IsRequired,
+				Choices:  choices,
+				Help:     option.HelpText,
+				MetaVar:  option.getPositionalUsage(),
+			})
+			continue
+		}
+
+		opt := optionDescription{
+			Short:    []string{},
+			Long:     []string{},
+			Dest:     option.DestName,
+			Type:     option.getTypeName(),
+			Nargs:    option.ArgNum,
+			Default:  option.DefaultVal,
+			Required: option.IsRequired,
+			Choices:  choices,
+			Help:     option.HelpText,
+		}
+		if option.ArgNum != "0" {
+			opt.MetaVar = strings.TrimSpace(option.getArgsUsage())
+		}
+		for _, publicName := range option.PublicNames {
+			if option.isAlias(publicName) == true {
+				continue
+			} else if len(publicName) == 1 {
+				opt.Short = append(opt.Short, publicName)
+			} else if len(publicName) > 1 {
+				opt.Long = append(opt.Long, publicName)
+			}
+		}
+		description.Options = append(description.Options, opt)
+	}
+
+	for _, command := range p.Commands {
+		description.Commands = append(description.Commands, command.describe(command.CommandName))
+	}
+	return description
+}
+
+// getTypeName returns the name of the type of the option's value, as described
+// by DescribeJSON.
+func (f *Option) getTypeName() string {
+	if _, ok := f.getFlagValue(); ok == true || (f.IsNegatable == true && f.ArgNum == "0") {
+		return "bool"
+	} else if f.DesiredAction != nil && reflect.ValueOf(f.DesiredAction).Pointer() == reflect.ValueOf(Count).Pointer() {
+		return "count"
+	} else if f.ArgNum == "0" {
+		return "none"
+	} else if f.IsDuration == true {
+		return "duration"
+	} else if f.ExpectedType != reflect.Invalid {
+		return f.ExpectedType.String()
+	}
+	return "string"
+}
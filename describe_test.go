@@ -0,0 +1,35 @@
+package argparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestParserDescribeJSON tests that the JSON description of a parser matches the
+// expected golden file, and that it can be decoded.
+func TestParserDescribeJSON(t *testing.T) {
+	p := newCompletionParser()
+	p.AddOptions(
+		NewCounter("q quiet", "quiet", "Decrease verbosity"),
+		NewOption("j jobs", "jobs", "Number of jobs").Nargs("1").Action(Store).Type(reflect.Int).Default("4").Required(),
+		NewDuration("timeout", "timeout", "Time to wait").Alias("wait"),
+		NewFlag("debug", "debug", "Internal debugging").Hidden(),
+		NewArg("paths", "paths", "Paths to include").Nargs("*"),
+	)
+
+	var b bytes.Buffer
+	if err := p.DescribeJSON(&b); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	checkGolden(t, "describe.json", b.Bytes())
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if decoded["name"] != "proj" || len(decoded["commands"].([]interface{})) != 2 {
+		t.Errorf("Unexpected name or commands within the description: %v", decoded)
+	}
+}
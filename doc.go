@@ -5,14 +5,14 @@
 //
 //		> exc --help
 //
-//		usage: main [-h] [-v] [-e] [-x  ...] [-n] [-f] [-k] [p PATTERN] [s SPLIT] [c CHAR]
+//		usage: main [-h] [-v] [-e] [-x  ...] [-n] [-f] [-k] <pattern> <split> <char>
 //
 //		Construct and execute arguments from Stdin
 //
 //		positional arguments:
-//		  [p PATTERN]         Stdin regex grouping pattern
-//		  [s SPLIT]           Delimiting regex for Stdin
-//		  [c CHAR]            Replacement string for argument parsing
+//		  <pattern>           Stdin regex grouping pattern
+//		  <split>             Delimiting regex for Stdin
+//		  <char>              Replacement string for argument parsing
 //
 //		optional arguments:
 //		  -h, --help          Show program help
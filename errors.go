@@ -2,9 +2,64 @@ package argparse
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
+// AmbiguousOptionErr indicates that an abbreviated option matches more than one
+// option. Index and Token identify the argument containing the option.
+type AmbiguousOptionErr struct {
+	name       string
+	candidates []string
+	Index      int    // Index of the argument containing the option.
+	Token      string // The argument containing the option.
+}
+
+// Error will return a string error message for the AmbiguousOptionErr
+func (err AmbiguousOptionErr) Error() string {
+	msg := "ambiguous option \"--%s\" (could be: %s)"
+	return fmt.Sprintf(msg, err.name, strings.Join(err.candidates, ", "))
+}
+
+// ConfigFileErr indicates that the option values within a config file could not
+// be read.
+type ConfigFileErr struct {
+	name string
+	err  error
+}
+
+// Error will return a string error message for the ConfigFileErr
+func (err ConfigFileErr) Error() string {
+	msg := "config file \"%s\": %s"
+	return fmt.Sprintf(msg, err.name, err.err.Error())
+}
+
+// ConfigValueErr indicates that a value within a config file is not valid for
+// the option it is associated with.
+type ConfigValueErr struct {
+	key string
+	err error
+}
+
+// Error will return a string error message for the ConfigValueErr
+func (err ConfigValueErr) Error() string {
+	msg := "%s (from config key \"%s\")"
+	return fmt.Sprintf(msg, err.err.Error(), err.key)
+}
+
+// InvalidBindErr indicates that a struct, or one of its fields, cannot be bound
+// to the parser's options.
+type InvalidBindErr struct {
+	name   string
+	reason string
+}
+
+// Error will return a string error message for the InvalidBindErr
+func (err InvalidBindErr) Error() string {
+	msg := "cannot bind %s: %s"
+	return fmt.Sprintf(msg, err.name, err.reason)
+}
+
 // InvalidChoiceErr indicates that an argument is not among the valid choices
 // for the option.
 type InvalidChoiceErr struct {
@@ -19,6 +74,19 @@ func (err InvalidChoiceErr) Error() string {
 
 }
 
+// InvalidCommandErr indicates that a subcommand with the provided name does
+// not exist.
+type InvalidCommandErr struct {
+	name     string
+	commands []string
+}
+
+// Error will return a string error message for the InvalidCommandErr
+func (err InvalidCommandErr) Error() string {
+	msg := "invalid command \"%s\" (choose from: %s)"
+	return fmt.Sprintf(msg, err.name, strings.Join(err.commands, ", "))
+}
+
 // InvalidFlagNameErr indicates that an argument with the provided public name
 // not exist.
 type InvalidFlagNameErr struct {
@@ -32,14 +100,47 @@ func (err InvalidFlagNameErr) Error() string {
 
 }
 
-// InvalidOptionErr indicates that an option is invalid.
+// InvalidKeyValueErr indicates that an argument is not in the KEY=VALUE form
+// expected by the option.
+type InvalidKeyValueErr struct {
+	opt Option
+	arg string
+}
+
+// Error will return a string error message for the InvalidKeyValueErr
+func (err InvalidKeyValueErr) Error() string {
+	msg := "%s: expected KEY=VALUE, got \"%s\""
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg)
+}
+
+// InvalidDurationErr indicates that an argument cannot be parsed as a duration
+// for the option.
+type InvalidDurationErr struct {
+	opt Option
+	arg string
+}
+
+// Error will return a string error message for the InvalidDurationErr
+func (err InvalidDurationErr) Error() string {
+	msg := "%s: invalid duration \"%s\""
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg)
+}
+
+// InvalidOptionErr indicates that an option is invalid. Index and Token identify
+// the argument containing the option.
 type InvalidOptionErr struct {
-	name string
+	name       string
+	suggestion string
+	Index      int    // Index of the argument containing the option.
+	Token      string // The argument containing the option.
 }
 
 // Error will return a string error message for the InvalidFlagNameErr
 func (err InvalidOptionErr) Error() string {
 	msg := "invalid option \"%s\""
+	if err.suggestion != "" {
+		return fmt.Sprintf(msg+" (did you mean --%s?)", err.name, err.suggestion)
+	}
 	return fmt.Sprintf(msg, err.name)
 
 }
@@ -53,10 +154,130 @@ type InvalidTypeErr struct {
 
 // Error will return a string error message for the InvalidTypeErr
 func (err InvalidTypeErr) Error() string {
-	msg := "%s: invalid %s value: \"%s\""
-	return fmt.Sprintf(msg, err.opt.DisplayName(), err.opt.ExpectedType.String(), err.arg)
+	msg := "%s: invalid value \"%s\": expected %s"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg, err.expected())
+}
+
+// expected returns a description of the values accepted by the option's
+// expected type, such as `integer`.
+func (err InvalidTypeErr) expected() string {
+	switch err.opt.ExpectedType {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "unsigned integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return err.opt.ExpectedType.String()
+}
+
+// InvalidValueErr indicates that an argument was rejected by one of the option's
+// validators.
+type InvalidValueErr struct {
+	opt Option
+	arg string
+	err error
+}
+
+// Error will return a string error message for the InvalidValueErr
+func (err InvalidValueErr) Error() string {
+	msg := "%s: invalid value \"%s\": %s"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg, err.err.Error())
+}
+
+// OccurrencesErr indicates that an option was present fewer times than its
+// minimum, or more times than its maximum, number of occurrences.
+type OccurrencesErr struct {
+	opt      Option
+	received int
+}
+
+// Error will return a string error message for the OccurrencesErr
+func (err OccurrencesErr) Error() string {
+	plural := func(n int, noun string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, noun)
+		}
+		return fmt.Sprintf("%d %ss", n, noun)
+	}
+
+	if err.received < err.opt.MinCount {
+		msg := "%s: requires at least %s"
+		return fmt.Sprintf(msg, err.opt.DisplayName(), plural(err.opt.MinCount, "value"))
+	}
+	msg := "%s: may be specified at most %s"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), plural(err.opt.MaxCount, "time"))
 }
 
+// OpenFileErr indicates that the file named by an argument could not be opened
+// for the option.
+type OpenFileErr struct {
+	opt Option
+	arg string
+	err error
+}
+
+// Error will return a string error message for the OpenFileErr
+func (err OpenFileErr) Error() string {
+	msg := "%s: cannot open \"%s\": %s"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg, err.err.Error())
+}
+
+// OptionAsValueErr indicates that an option expecting a value was followed by
+// another option instead, which must be attached to be used as the value.
+type OptionAsValueErr struct {
+	opt      Option
+	arg      string
+	attached string
+}
+
+// Error will return a string error message for the OptionAsValueErr
+func (err OptionAsValueErr) Error() string {
+	msg := "%s: expected a value, but received the option \"%s\" (use \"%s\" to pass it as the value)"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.arg, err.attached)
+}
+
+// ParseErrors contains every error which occurred while parsing arguments, in
+// the order they occurred.
+type ParseErrors []error
+
+// Errors will return each of the errors within the ParseErrors
+func (errs ParseErrors) Errors() []error {
+	return []error(errs)
+}
+
+// Error will return a string error message for the ParseErrors, containing the
+// message of each error on its own line
+func (errs ParseErrors) Error() string {
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ResponseFileErr indicates that the arguments within a response file could not
+// be read.
+type ResponseFileErr struct {
+	name string
+	err  error
+}
+
+// Error will return a string error message for the ResponseFileErr
+func (err ResponseFileErr) Error() string {
+	msg := "response file \"%s\": %s"
+	return fmt.Sprintf(msg, err.name, err.err.Error())
+}
+
+// ShowCompletionErr indicates that the program was invoked by a shell completion
+// script, and has output the candidates for completing an argument.
+type ShowCompletionErr struct{}
+
+func (err ShowCompletionErr) Error() string { return "" }
+
 // ShowHelpErr indicates that the program was instructed to show it's help text.
 type ShowHelpErr struct{}
 
@@ -67,6 +288,13 @@ type ShowVersionErr struct{}
 
 func (err ShowVersionErr) Error() string { return "" }
 
+// StopErr indicates that parsing was instructed to stop, such as by a Callback
+// action. Parse returns successfully, without checking for required options or
+// binding positional options, unless an earlier option caused an error.
+type StopErr struct{}
+
+func (err StopErr) Error() string { return "parsing stopped" }
+
 // TooFewArgsErr indicated that not enough arguments were provided for the option.
 type TooFewArgsErr struct {
 	opt Option
@@ -78,6 +306,45 @@ func (err TooFewArgsErr) Error() string {
 	return fmt.Sprintf(msg, err.opt.DisplayName())
 }
 
+// TooFewValuesErr indicates that an option expecting several values was followed
+// by fewer of them, such as when another option appears among its values.
+type TooFewValuesErr struct {
+	opt      Option
+	expected int
+	received int
+}
+
+// Error will return a string error message for the TooFewValuesErr
+func (err TooFewValuesErr) Error() string {
+	msg := "%s: expected %d values, got %d"
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.expected, err.received)
+}
+
+// UnexpectedValueErr indicates that a value was attached to an option which
+// does not expect any arguments.
+type UnexpectedValueErr struct {
+	opt   Option
+	value string
+}
+
+// Error will return a string error message for the UnexpectedValueErr
+func (err UnexpectedValueErr) Error() string {
+	msg := "%s: unexpected value \"%s\""
+	return fmt.Sprintf(msg, err.opt.DisplayName(), err.value)
+}
+
+// TooManyArgsErr indicates that arguments were provided which were not consumed
+// by any of the parser's options.
+type TooManyArgsErr struct {
+	args []string
+}
+
+// Error will return a string error message for the TooManyArgsErr
+func (err TooManyArgsErr) Error() string {
+	msg := "unrecognized arguments: %s"
+	return fmt.Sprintf(msg, strings.Join(err.args, " "))
+}
+
 // MissingOneOrMoreArgsErr indicated that not enough arguments were provided,
 // when one or more arguments were expected, for the option.
 type MissingOneOrMoreArgsErr struct {
@@ -90,13 +357,43 @@ func (err MissingOneOrMoreArgsErr) Error() string {
 	return fmt.Sprintf(msg, err.opt.DisplayName())
 }
 
-// MissingOptionErr indicated that an option was required but is missing.
+// MissingOptionErr indicated that one or more options were required but are missing.
 type MissingOptionErr struct {
-	name string
+	names []string
 }
 
 // Error will return a string error message for the MissingOptionErr
 func (err MissingOptionErr) Error() string {
-	msg := "option \"%s\" required"
-	return fmt.Sprintf(msg, err.name)
+	if len(err.names) == 1 {
+		msg := "option \"%s\" required"
+		return fmt.Sprintf(msg, err.names[0])
+	}
+
+	msg := "options \"%s\" required"
+	return fmt.Sprintf(msg, strings.Join(err.names, "\", \""))
+}
+
+// MutuallyExclusiveErr indicates that options which cannot be used together
+// were provided.
+type MutuallyExclusiveErr struct {
+	names []string
+}
+
+// Error will return a string error message for the MutuallyExclusiveErr
+func (err MutuallyExclusiveErr) Error() string {
+	last := len(err.names) - 1
+	msg := "%s and %s are mutually exclusive"
+	return fmt.Sprintf(msg, strings.Join(err.names[:last], ", "), err.names[last])
+}
+
+// RequiredOneOfErr indicates that none of the options within a group requiring
+// exactly one of them were provided.
+type RequiredOneOfErr struct {
+	names []string
+}
+
+// Error will return a string error message for the RequiredOneOfErr
+func (err RequiredOneOfErr) Error() string {
+	msg := "exactly one of %s is required"
+	return fmt.Sprintf(msg, strings.Join(err.names, ", "))
 }
@@ -0,0 +1,183 @@
+package argparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Explain returns a description of how the parser interprets the provided
+// arguments, one line at a time: each option along with the values bound to it,
+// each positional argument along with the option it is bound to, any selected
+// command, and the position of a `--` terminator. No actions are run, and the
+// parser's namespace remains unmodified, which helps to diagnose an option which
+// did not take effect.
+func (p *Parser) Explain(allArgs ...string) string {
+	return strings.Join(p.explain(allArgs, 0), "\n")
+}
+
+// explain returns the lines describing the provided arguments, as described by
+// Explain, where the offset is the index of the first argument within the
+// program's arguments.
+func (p *Parser) explain(allArgs []string, offset int) []string {
+	p.addDefaultHelp()
+	allArgs = p.plusOptions(p.foldLongOptions(p.dashLongOptions(p.slashOptions(allArgs))))
+
+	if len(p.Commands) > 0 {
+		if index := p.commandIndex(allArgs...); index >= 0 {
+			lines := p.explain(allArgs[:index], offset)
+			name := allArgs[index]
+			command, err := p.getCommand(name)
+			if err != nil && name == "help" && p.HelpDisabled == false {
+				return append(lines, "command help shows the help text")
+			} else if err != nil {
+				return append(lines, fmt.Sprintf("command %s: %s", name, err.Error()))
+			}
+			return append(append(lines, "command "+name), command.explain(allArgs[index+1:], offset+index+1)...)
+		}
+	}
+
+	terminator := -1
+	for i, arg := range allArgs {
+		if arg == "--" {
+			terminator = i
+			break
+		}
+	}
+
+	// When stopping early, the arguments from the first positional or
+	// unrecognized argument onwards are passed through uninterpreted.
+	var passthrough []string
+	if p.StopEarly == true {
+		index := p.stopIndex(true, allArgs...)
+		if index < terminator {
+			terminator = -1
+		} else if index == terminator {
+			index++
+		}
+		passthrough = allArgs[index:]
+		allArgs = allArgs[:index]
+	}
+
+	// The arguments captured by a remainder option are never interpreted.
+	remainder, index := p.remainderIndex(allArgs)
+	var raw []string
+	if remainder != nil {
+		raw = allArgs[index:]
+		allArgs = allArgs[:index]
+	}
+
+	// Without interspersed arguments, the options end at the first positional
+	// argument, after which any `--` is itself a positional argument.
+	strict := -1
+	if terminated := p.terminatePositionals(allArgs); len(terminated) > len(allArgs) {
+		strict, terminator = p.stopIndex(false, allArgs...), -1
+		allArgs = terminated
+	}
+
+	var lines []string
+
+	extracted, args, values := p.extractArgs(allArgs)
+	for i, extractedOption := range extracted {
+		name := prefixedName(extractedOption.name)
+		option, err := p.matchOption(extractedOption.name)
+		if err != nil {
+			_, unknown := err.(InvalidOptionErr)
+			first, whole := p.unknownCluster(extracted, i)
+			if negated := p.matchNegation(extractedOption.name); negated != nil && extractedOption.hasValue == false {
+				lines = append(lines, fmt.Sprintf("option %s negates %s", name, negated.DisplayName()))
+			} else if unknown == true && whole == true && first == false {
+				continue
+			} else if unknown == true && negated == nil && p.IgnoreUnknown == true {
+				lines = append(lines, fmt.Sprintf("unknown %s collected", quoteAll(unknownArgs(extracted, i, allArgs, whole))))
+			} else if unknown == true && whole == true {
+				token := allArgs[extractedOption.index]
+				lines = append(lines, fmt.Sprintf("option %s: %s", token, InvalidOptionErr{name: token}.Error()))
+			} else {
+				lines = append(lines, fmt.Sprintf("option %s: %s", name, err.Error()))
+			}
+			continue
+		}
+
+		var bound []string
+		if extractedOption.hasValue == true {
+			bound = append(bound, extractedOption.value)
+		}
+
+		// An option expecting several values only takes those following it.
+		if num, err := strconv.Atoi(option.ArgNum); err == nil && num > 1 && extractedOption.hasValue == true {
+			bound = append(bound, values[extractedOption.index]...)
+			if len(bound) < num {
+				lines = append(lines, fmt.Sprintf("option %s: expected %d values, got %d", name, num, len(bound)))
+				continue
+			}
+		} else if extractedOption.hasValue == true || option.IsValueOptional == false {
+			count := explainCount(option.ArgNum, len(args)) - len(bound)
+			if count > 0 {
+				bound, args = append(bound, args[:count]...), args[count:]
+			}
+		}
+
+		if len(bound) == 0 {
+			lines = append(lines, "option "+name)
+		} else {
+			lines = append(lines, fmt.Sprintf("option %s = %s", name, quoteAll(bound)))
+		}
+	}
+
+	for _, option := range p.Options {
+		if option.IsPositional == false {
+			continue
+		}
+		count := explainCount(option.ArgNum, len(args))
+		for _, arg := range args[:count] {
+			lines = append(lines, fmt.Sprintf("positional %q bound to %s", arg, option.DestName))
+		}
+		args = args[count:]
+	}
+	for _, arg := range args {
+		lines = append(lines, fmt.Sprintf("positional %q", arg))
+	}
+	if len(raw) > 0 {
+		lines = append(lines, fmt.Sprintf("remainder %s bound to %s", quoteAll(raw), remainder.DestName))
+	}
+
+	if strict >= 0 {
+		lines = append(lines, fmt.Sprintf("options end at argument %d, as arguments are not interspersed", offset+strict))
+	}
+	if terminator >= 0 {
+		lines = append(lines, fmt.Sprintf("terminator -- at argument %d; following arguments are positional", offset+terminator))
+	}
+	if len(passthrough) > 0 {
+		lines = append(lines, fmt.Sprintf("stopped early; passing through %s", quoteAll(passthrough)))
+	}
+	return lines
+}
+
+// explainCount returns the number of the available arguments which are bound to
+// an option expecting the provided number of arguments.
+func explainCount(argNum string, available int) int {
+	count := 0
+	switch argNum {
+	case "?":
+		count = 1
+	case "*", "+", "r", "R":
+		count = available
+	default:
+		count, _ = strconv.Atoi(argNum)
+	}
+
+	if count > available {
+		return available
+	}
+	return count
+}
+
+// quoteAll returns the provided values, each quoted, delimited by spaces.
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, " ")
+}
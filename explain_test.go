@@ -0,0 +1,139 @@
+package argparse
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParserExplain tests the Explain method to ensure options are attributed
+// their values, and remaining arguments are attributed as positionals, without
+// modifying the parser's namespace.
+func TestParserExplain(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("o out", "out", "output file").Nargs("1").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewFlag("color", "color", "colored output").Negatable(),
+	)
+
+	explanation := p.Explain("--out", "file.txt", "extra")
+	expected := "option --out = \"file.txt\"\npositional \"extra\""
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+	if p.Namespace.KeyExists("out") == true {
+		t.Errorf("Expected the namespace to remain unmodified, but received: %v", p.Namespace.Mapping)
+	}
+
+	explanation = p.Explain("-vo", "a.txt", "--no-color", "--bogus", "--", "--verbose")
+	for _, line := range []string{
+		"option -v",
+		"option -o = \"a.txt\"",
+		"option --no-color negates --color",
+		"option --bogus: invalid option \"bogus\"",
+		"positional \"--verbose\"",
+		"terminator -- at argument 4; following arguments are positional",
+	} {
+		if strings.Contains(explanation, line+"\n") == false && strings.HasSuffix(explanation, line) == false {
+			t.Errorf("Expected explanation to contain '%s', but received: '%s'", line, explanation)
+		}
+	}
+}
+
+// TestParserExplain_Positionals tests the Explain method to ensure positional
+// arguments are attributed to the positional options they are bound to, and the
+// arguments following a command are explained using that command's parser.
+func TestParserExplain_Positionals(t *testing.T) {
+	p := NewParser("parser")
+	add := p.AddCommand("add", "add files")
+	add.AddOptions(
+		NewOption("dst", "dst", "destination").Positional().Nargs("1").Action(Store),
+		NewOption("files", "files", "files to add").Positional().Nargs("*").Action(Store),
+	)
+
+	explanation := p.Explain("add", "out", "a.txt", "b.txt")
+	expected := "command add\npositional \"out\" bound to dst\npositional \"a.txt\" bound to files\npositional \"b.txt\" bound to files"
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
+
+// TestParserExplain_NotInterspersed tests the Explain method to ensure options
+// following the first positional argument are explained as positionals when
+// arguments are not interspersed.
+func TestParserExplain_NotInterspersed(t *testing.T) {
+	p := NewParser("parser").SetInterspersed(false)
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+
+	explanation := p.Explain("-v", "file", "-v")
+	expected := "option -v\npositional \"file\"\npositional \"-v\"\noptions end at argument 1, as arguments are not interspersed"
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
+
+// TestParserExplain_MultipleValues tests the Explain method to ensure an option
+// expecting several values is bound to the arguments following it, and that too
+// few of them are explained as an error.
+func TestParserExplain_MultipleValues(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("c coords", "coords", "coordinates").Nargs("3").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	explanation := p.Explain("file", "--coords", "1", "2", "3")
+	expected := "option --coords = \"1\" \"2\" \"3\"\npositional \"file\""
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+
+	explanation = p.Explain("--coords", "1", "-v", "2")
+	expected = "option --coords: expected 3 values, got 1\noption -v\npositional \"2\""
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
+
+// TestParserExplain_Remainder tests the Explain method to ensure the arguments
+// captured by a remainder option are explained verbatim, without interpreting
+// those resembling options.
+func TestParserExplain_Remainder(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewArg("plugin", "plugin", "plugin to run"),
+		NewRemainder("args", "args", "arguments for the plugin"),
+	)
+
+	explanation := p.Explain("myplugin", "--foo", "-b")
+	expected := "positional \"myplugin\" bound to plugin\nremainder \"--foo\" \"-b\" bound to args"
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
+
+// TestParserExplain_IgnoreUnknown tests to ensure that unknown options, along
+// with their values, are described as collected when unknown options are ignored.
+func TestParserExplain_IgnoreUnknown(t *testing.T) {
+	p := NewParser("parser").SetIgnoreUnknown(true)
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+
+	explanation := p.Explain("--new-flag", "value", "-v")
+	expected := "unknown \"--new-flag\" \"value\" collected\noption -v"
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
+
+// TestParserExplain_UnknownCluster tests to ensure that a cluster of short
+// options which are all unknown is described once, as a whole.
+func TestParserExplain_UnknownCluster(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+
+	explanation := p.Explain("-xyz", "-vy")
+	expected := "option -xyz: invalid option \"-xyz\"\noption -v\noption -y: invalid option \"y\""
+	if explanation != expected {
+		t.Errorf("Expected explanation '%s', but received: '%s'", expected, explanation)
+	}
+}
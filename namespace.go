@@ -1,6 +1,25 @@
 package argparse
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// HostPort contains the host and port of a network address, such as "0.0.0.0"
+// and "8080" for "0.0.0.0:8080".
+type HostPort struct {
+	Host string
+	Port string
+}
+
+// String returns the address represented by the HostPort.
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, hp.Port)
+}
 
 // Namespace is a struct for storing the key-value pairings between
 // options' destinations and their associated values.
@@ -18,6 +37,112 @@ func (n *Namespace) Get(key string) interface{} {
 	return n.Mapping[key]
 }
 
+// ByteSize will retrieve the value at the specified key as a number of bytes,
+// such as 10000000 for "10MB" or 10485760 for "10MiB". An error is returned if
+// the key does not exist, or its value cannot be converted.
+func (n *Namespace) ByteSize(key string) (int64, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return 0, err
+	}
+
+	str, _ := value.(string)
+	size, err := parseByteSize(str)
+	if err != nil {
+		return 0, fmt.Errorf("Key \"%s\" does not contain a size value: \"%v\"", key, value)
+	}
+	return size, nil
+}
+
+// Duration will retrieve the value at the specified key as a time.Duration, such
+// as "1h30m". An error is returned if the key does not exist, or its value cannot
+// be converted.
+func (n *Namespace) Duration(key string) (time.Duration, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return 0, err
+	}
+
+	str, _ := value.(string)
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("Key \"%s\" does not contain a duration value: \"%v\"", key, value)
+	}
+	return d, nil
+}
+
+// File will retrieve the *os.File at the specified key, as opened by the OpenFile
+// action. Otherwise, nil is returned.
+func (n *Namespace) File(key string) *os.File {
+	file, _ := n.Get(key).(*os.File)
+	return file
+}
+
+// Float will retrieve the value at the specified key as a float64. An error is
+// returned if the key does not exist, or its value cannot be converted.
+func (n *Namespace) Float(key string) (float64, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return 0, err
+	}
+
+	str, _ := value.(string)
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Key \"%s\" does not contain a float value: \"%v\"", key, value)
+	}
+	return f, nil
+}
+
+// HostPort will retrieve the value at the specified key as a HostPort, such as
+// "0.0.0.0:8080". An error is returned if the key does not exist, or its value
+// cannot be converted.
+func (n *Namespace) HostPort(key string) (HostPort, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return HostPort{}, err
+	}
+
+	str, _ := value.(string)
+	host, port, err := net.SplitHostPort(str)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("Key \"%s\" does not contain a host and port value: \"%v\"", key, value)
+	}
+	return HostPort{host, port}, nil
+}
+
+// Int will retrieve the value at the specified key as an int. An error is
+// returned if the key does not exist, or its value cannot be converted.
+func (n *Namespace) Int(key string) (int, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return 0, err
+	}
+
+	str, _ := value.(string)
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("Key \"%s\" does not contain an int value: \"%v\"", key, value)
+	}
+	return i, nil
+}
+
+// IP will retrieve the value at the specified key as a net.IP. An error is
+// returned if the key does not exist, or its value cannot be converted.
+func (n *Namespace) IP(key string) (net.IP, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return nil, err
+	}
+
+	str, _ := value.(string)
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return nil, fmt.Errorf("Key \"%s\" does not contain an IP address value: \"%v\"", key, value)
+	}
+	return ip, nil
+}
+
 // KeyExists returns a bool indicating true if the key does exist in the mapping,
 // or otherwise false.
 func (n *Namespace) KeyExists(key string) bool {
@@ -27,6 +152,30 @@ func (n *Namespace) KeyExists(key string) bool {
 	return false
 }
 
+// Map will retrieve the map[string]string at the specified key, as stored by
+// the StoreMap action. Otherwise, nil is returned.
+func (n *Namespace) Map(key string) map[string]string {
+	mapping, _ := n.Get(key).(map[string]string)
+	return mapping
+}
+
+// Percent will retrieve the value at the specified key as a fraction, such as
+// 0.75 for "75%". An error is returned if the key does not exist, or its value
+// cannot be converted.
+func (n *Namespace) Percent(key string) (float64, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return 0, err
+	}
+
+	str, _ := value.(string)
+	percent, err := parsePercent(str)
+	if err != nil {
+		return 0, fmt.Errorf("Key \"%s\" does not contain a percentage value: \"%v\"", key, value)
+	}
+	return percent, nil
+}
+
 // Require will assert that all the specified keys exist in the namespace.
 func (n *Namespace) Require(keys ...string) error {
 	for _, key := range keys {
@@ -83,6 +232,22 @@ func (n *Namespace) Try(key string) (interface{}, error) {
 	return n.Mapping[key], nil
 }
 
+// URL will retrieve the value at the specified key as a *url.URL. An error is
+// returned if the key does not exist, or its value cannot be converted.
+func (n *Namespace) URL(key string) (*url.URL, error) {
+	value, err := n.Try(key)
+	if err != nil {
+		return nil, err
+	}
+
+	str, _ := value.(string)
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("Key \"%s\" does not contain a URL value: \"%v\"", key, value)
+	}
+	return u, nil
+}
+
 // Create a new pointer to an Namespace instance.
 func NewNamespace() *Namespace {
 	n := new(Namespace)
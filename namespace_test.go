@@ -0,0 +1,132 @@
+package argparse
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestNamespaceDuration tests the Duration method to ensure valid values are
+// converted to a time.Duration, while invalid and missing values result in an error.
+func TestNamespaceDuration(t *testing.T) {
+	n := NewNamespace()
+	n.Set("valid", "1h30m").Set("invalid", "30x")
+
+	if d, err := n.Duration("valid"); err != nil || d != 90*time.Minute {
+		t.Errorf("Expected 1h30m0s, but received: %v (%v)", d, err)
+	}
+
+	if _, err := n.Duration("invalid"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+
+	if _, err := n.Duration("missing"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+}
+
+// TestNamespaceNetworkValues tests the IP, HostPort, and URL methods to ensure
+// valid values are converted, while invalid and missing values result in an error.
+func TestNamespaceNetworkValues(t *testing.T) {
+	n := NewNamespace()
+	n.Set("ip", "192.168.0.1").Set("hostport", "[::1]:80").Set("url", "https://host/path").Set("invalid", "%zz")
+
+	if ip, err := n.IP("ip"); err != nil || ip.String() != "192.168.0.1" {
+		t.Errorf("Expected 192.168.0.1, but received: %v (%v)", ip, err)
+	}
+	if hp, err := n.HostPort("hostport"); err != nil || hp != (HostPort{"::1", "80"}) || hp.String() != "[::1]:80" {
+		t.Errorf("Expected [::1]:80, but received: %v (%v)", hp, err)
+	}
+	if u, err := n.URL("url"); err != nil || u.String() != "https://host/path" {
+		t.Errorf("Expected https://host/path, but received: %v (%v)", u, err)
+	}
+
+	for _, key := range []string{"invalid", "missing"} {
+		if _, err := n.IP(key); err == nil {
+			t.Errorf("An error was expected for IP '%s' but did not occur", key)
+		}
+		if _, err := n.HostPort(key); err == nil {
+			t.Errorf("An error was expected for HostPort '%s' but did not occur", key)
+		}
+		if _, err := n.URL(key); err == nil {
+			t.Errorf("An error was expected for URL '%s' but did not occur", key)
+		}
+	}
+}
+
+// TestNamespaceFloat tests the Float method to ensure valid values are converted
+// to a float64, while invalid and missing values result in an error.
+func TestNamespaceFloat(t *testing.T) {
+	n := NewNamespace()
+	n.Set("valid", "3.14").Set("negative", "-2.5").Set("invalid", "abc")
+
+	if f, err := n.Float("valid"); err != nil || f != 3.14 {
+		t.Errorf("Expected 3.14, but received: %v (%v)", f, err)
+	}
+
+	if f, err := n.Float("negative"); err != nil || f != -2.5 {
+		t.Errorf("Expected -2.5, but received: %v (%v)", f, err)
+	}
+
+	if _, err := n.Float("invalid"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+
+	if _, err := n.Float("missing"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+}
+
+// TestNamespaceInt tests the Int method to ensure valid values are converted
+// to an int, while invalid and missing values result in an error.
+func TestNamespaceInt(t *testing.T) {
+	n := NewNamespace()
+	n.Set("valid", "42").Set("negative", "-7").Set("invalid", "abc").Set("slice", []string{"1"})
+
+	if i, err := n.Int("valid"); err != nil || i != 42 {
+		t.Errorf("Expected 42, but received: %v (%v)", i, err)
+	}
+
+	if i, err := n.Int("negative"); err != nil || i != -7 {
+		t.Errorf("Expected -7, but received: %v (%v)", i, err)
+	}
+
+	for _, key := range []string{"invalid", "slice", "missing"} {
+		if _, err := n.Int(key); err == nil {
+			t.Errorf("An error was expected for key '%s' but did not occur", key)
+		}
+	}
+}
+
+// TestNamespaceInt_Parsed tests to ensure typed options parsed by a Parser can be
+// retrieved as typed values, using their default when absent.
+func TestNamespaceInt_Parsed(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("c count", "count", "count").Nargs("1").Action(Store).Type(reflect.Int).Default("10"),
+		NewOption("r ratio", "ratio", "ratio").Nargs("1").Action(Store).Type(reflect.Float64).Default("0.5"),
+	)
+
+	ns, _, err := p.Parse("--count", "-5")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if i, err := ns.Int("count"); err != nil || i != -5 {
+		t.Errorf("Expected -5, but received: %v (%v)", i, err)
+	}
+
+	if f, err := ns.Float("ratio"); err != nil || f != 0.5 {
+		t.Errorf("Expected 0.5, but received: %v (%v)", f, err)
+	}
+
+	tests := map[string][]string{
+		`-c, --count: invalid value "abc": expected integer`: {"--count", "abc"},
+		`-r, --ratio: invalid value "half": expected float`:  {"--ratio", "half"},
+	}
+	for expected, args := range tests {
+		if _, _, err = p.Parse(args...); err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+		}
+	}
+}
@@ -1,27 +1,126 @@
 package argparse
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // NewFlag initializes a new Option pointer, sets its Nargs to 0, its action
-// to StoreTrue, and its default value to false.
+// to StoreTrue, and its default value to false. The flag is negatable, so its
+// long names can be prefixed with `no-` to store false instead.
 func NewFlag(names, dest, help string) *Option {
 	opt := NewOption(names, dest, help)
-	opt.Nargs("0").Action(StoreTrue).Default("false")
+	opt.Nargs("0").Action(StoreTrue).Default("false").Negatable()
 
 	return opt
 }
 
+// NewCounter initializes a new Option pointer, sets its Nargs to 0, its action
+// to Count, and its default value to 0.
+func NewCounter(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("0").Action(Count).Default("0")
+}
+
+// NewMap initializes a new Option pointer, sets its Nargs to 1 and its action to
+// StoreMap, collecting each KEY=VALUE argument into a map.
+func NewMap(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(StoreMap).MetaVar("KEY=VALUE")
+}
+
+// NewStringSlice initializes a new Option pointer, sets its Nargs to 1 and its
+// action to AppendSplit, splitting each argument on the provided separator into
+// the option's slice, such as `PATH`-style values like `a:b:c`. The value is
+// retrieved using Namespace.Slice.
+func NewStringSlice(names, dest, sep, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(AppendSplit(sep)).MetaVar(join("", "VALUE", sep, "..."))
+}
+
+// NewSliceFromFile initializes a new Option pointer, sets its Nargs to 1 and its
+// action to AppendLines, reading the file named by its argument, such as
+// `--include-from list.txt`, and appending each of its lines into the option's
+// slice. The value is retrieved using Namespace.Slice.
+func NewSliceFromFile(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(AppendLines).MetaVar("file")
+}
+
+// NewDuration initializes a new Option pointer, sets its Nargs to 1 and its
+// action to Store, and expects its argument to be a duration, such as "1h30m".
+// The value is retrieved using Namespace.Duration.
+func NewDuration(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Duration().MetaVar("duration")
+}
+
+// NewFile initializes a new Option pointer, sets its Nargs to 1 and its action
+// to OpenFile, opening the file named by its argument using the provided flags,
+// such as os.O_RDONLY or os.O_WRONLY|os.O_CREATE. The file is retrieved using
+// Namespace.File.
+func NewFile(names, dest, help string, flag int) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(OpenFile(flag)).MetaVar("file")
+}
+
+// NewIP initializes a new Option pointer, sets its Nargs to 1 and its action to
+// Store, and validates its argument as an IP address, such as "10.0.0.1" or "::1".
+// The value is retrieved using Namespace.IP.
+func NewIP(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Validate(validateIP).MetaVar("ip")
+}
+
+// NewHostPort initializes a new Option pointer, sets its Nargs to 1 and its
+// action to Store, and validates its argument as a host and port, such as
+// "0.0.0.0:8080". The value is retrieved using Namespace.HostPort.
+func NewHostPort(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Validate(validateHostPort).MetaVar("host:port")
+}
+
+// NewByteSize initializes a new Option pointer, sets its Nargs to 1 and its
+// action to Store, and validates its argument as a size, such as "10MB" or
+// "512KiB", distinguishing decimal from binary units. The number of bytes is
+// retrieved using Namespace.ByteSize.
+func NewByteSize(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Validate(validateByteSize).MetaVar("size")
+}
+
+// NewPercent initializes a new Option pointer, sets its Nargs to 1 and its action
+// to Store, and validates its argument as a percentage, such as "75%" or "75".
+// The percentage is retrieved as a fraction, such as 0.75, using
+// Namespace.Percent.
+func NewPercent(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Validate(validatePercent).MetaVar("percent")
+}
+
+// NewURL initializes a new Option pointer, sets its Nargs to 1 and its action to
+// Store, and validates its argument as an absolute URL, such as
+// "https://host/path". The value is retrieved using Namespace.URL.
+func NewURL(names, dest, help string) *Option {
+	return NewOption(names, dest, help).Nargs("1").Action(Store).Validate(validateURL).MetaVar("url")
+}
+
 // NewArg initializes a new Option pointer, and sets its Nargs to 1, its
 // action to Store, and makes it a positional option.
 func NewArg(names, dest, help string) *Option {
 	return NewOption(names, dest, help).Nargs("1").Action(Store).Positional()
 }
 
+// NewRemainder initializes a new Option pointer, sets its Nargs to *, its action
+// to Store, and makes it a positional option capturing every argument from its
+// position onwards verbatim, including those resembling options, such as the
+// arguments to pass through to a plugin. Its position follows the arguments bound
+// to the positional options added before it.
+func NewRemainder(names, dest, help string) *Option {
+	opt := NewOption(names, dest, help).Nargs("*").Action(Store).Positional()
+	opt.IsRemainder = true
+
+	return opt
+}
+
 // ValidateChoice returns an error if the provided interface value
 // does not exists as valid choice for the provided flag.
 func ValidateChoice(f Option, arg string) error {
@@ -30,7 +129,7 @@ func ValidateChoice(f Option, arg string) error {
 	}
 
 	for _, c := range f.ValidChoices {
-		if arg == c {
+		if arg == c || (f.IgnoreCase == true && strings.EqualFold(arg, c)) {
 			return nil
 		}
 	}
@@ -69,6 +168,112 @@ func ValidateType(f Option, arg string) error {
 	return InvalidTypeErr{f, arg}
 }
 
+// ValidateDuration returns an error if the provided argument is not a duration,
+// as accepted by time.ParseDuration, when the option expects a duration.
+func ValidateDuration(f Option, arg string) error {
+	if f.IsDuration == false {
+		return nil
+	}
+	if _, err := time.ParseDuration(arg); err != nil {
+		return InvalidDurationErr{f, arg}
+	}
+	return nil
+}
+
+// ContextValidator returns an error for an invalid argument of an option,
+// provided the context of the parse, such as to cancel any I/O it performs.
+type ContextValidator func(ctx context.Context, arg string) error
+
+// ValidateCustom returns an error if the provided argument is rejected by any of
+// the option's validators. Validators are run in the order they were added, with
+// those added by ValidateContext provided context.Background().
+func ValidateCustom(f Option, arg string) error {
+	return validateCustom(context.Background(), f, arg)
+}
+
+// validateCustom returns an error if the provided argument is rejected by any of
+// the option's validators, as described by ValidateCustom, providing the context
+// to those added by ValidateContext.
+func validateCustom(ctx context.Context, f Option, arg string) error {
+	for _, validator := range f.Validators {
+		if err := validator(arg); err != nil {
+			return InvalidValueErr{f, arg, err}
+		}
+	}
+	for _, validator := range f.CtxValidators {
+		if err := validator(ctx, arg); err != nil {
+			return InvalidValueErr{f, arg, err}
+		}
+	}
+	return nil
+}
+
+// validateIP returns an error if the provided argument is not an IP address.
+func validateIP(arg string) error {
+	if net.ParseIP(arg) == nil {
+		return errors.New("not an IP address")
+	}
+	return nil
+}
+
+// validateHostPort returns an error if the provided argument is not a host and
+// port, such as "host:80" or "[::1]:80".
+func validateHostPort(arg string) error {
+	_, port, err := net.SplitHostPort(arg)
+	if err != nil {
+		if addrErr, ok := err.(*net.AddrError); ok == true {
+			return errors.New(addrErr.Err)
+		}
+		return err
+	} else if port == "" {
+		return errors.New("missing port")
+	}
+	return nil
+}
+
+// validateURL returns an error if the provided argument is not an absolute URL,
+// with both a scheme and a host.
+func validateURL(arg string) error {
+	u, err := url.Parse(arg)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok == true {
+			return urlErr.Err
+		}
+		return err
+	} else if u.Scheme == "" || u.Host == "" {
+		return errors.New("not an absolute URL")
+	}
+	return nil
+}
+
+// validateByteSize returns an error if the provided argument is not a size, such
+// as "10MB", or uses an unknown unit.
+func validateByteSize(arg string) error {
+	_, err := parseByteSize(arg)
+	return err
+}
+
+// validatePercent returns an error if the provided argument is not a percentage,
+// such as "75%".
+func validatePercent(arg string) error {
+	_, err := parsePercent(arg)
+	return err
+}
+
+// validateArg returns an error if the provided argument is not a valid choice,
+// is not of the expected type or duration, or is rejected by a validator for the
+// option. The context is provided to validators added by ValidateContext.
+func validateArg(ctx context.Context, f Option, arg string) error {
+	if err := ValidateChoice(f, arg); err != nil {
+		return err
+	} else if err := ValidateType(f, arg); err != nil {
+		return err
+	} else if err := ValidateDuration(f, arg); err != nil {
+		return err
+	}
+	return validateCustom(ctx, f, arg)
+}
+
 // NewOption instantiates a new Option pointer, initializing it as a boolean
 // flag. Multiple names should be delimited by a space; names should not
 // contain the prefix character.
@@ -100,18 +305,35 @@ func NewOption(names, dest, help string) *Option {
 //		f := argparse.NewFlag("-n --dry", "dryRun", "Enable dry-run mode")
 //		a := argparse.NewArg("--in", "inputPath", "Path to specified input file")
 type Option struct {
-	ArgNum        string       // Any digit, "+", "?", "*", or "r" and "R" to represent how many arguments an option can expect.
-	ConstVal      string       // A constant value to represent when used with the actions.StoreConst action.
-	DefaultVal    string       // A value to represent the Option by default.
-	DesiredAction Action       // A callback function which will parse an option and its arguments.
-	DestName      string       // A unique identifier to store an option's value within a namespace.
-	ExpectedType  reflect.Kind // The variable-type that an Option's arguments are to be interpretted as.
-	HelpText      string       // Text describing the usage/meaning of the Option.
-	IsRequired    bool         // Indicate if an Option must be present when parsing.
-	IsPositional  bool         // Indicate that an Option is identified by its position when parsing.
-	MetaVarText   []string     // Text used when representing an Option and its arguments.
-	PublicNames   []string     // Qualifiers for identifying the option during parsing.
-	ValidChoices  []string     // A slice of valid choices for arguments of the Option.
+	AliasNames      []string             // Public names accepted in place of an Option's other names, which are omitted from help text.
+	AliasWarnings   map[string]string    // Text describing the replacement of each deprecated alias, keyed by the alias.
+	ArgNum          string               // Any digit, "+", "?", "*", or "r" and "R" to represent how many arguments an option can expect.
+	Completer       CompleteFunc         // A callback returning the candidates for completing an Option's argument within a shell.
+	ConstVal        string               // A constant value to represent when used with the actions.StoreConst action.
+	CtxValidators   []ContextValidator   // Callbacks which return an error for invalid arguments of the Option, provided the parse's context.
+	DefaultVal      string               // A value to represent the Option by default.
+	DeprecatedText  string               // Text describing the replacement of a deprecated Option.
+	DesiredAction   Action               // A callback function which will parse an option and its arguments.
+	DestName        string               // A unique identifier to store an option's value within a namespace.
+	EnvVar          string               // An environment variable providing the option's value when it is not present.
+	ExpectedType    reflect.Kind         // The variable-type that an Option's arguments are to be interpretted as.
+	HelpText        string               // Text describing the usage/meaning of the Option.
+	IgnoreCase      bool                 // Indicate that arguments are matched against choices case-insensitively.
+	IgnoreEnv       bool                 // Indicate that an Option never takes its value from an environment variable.
+	IsDeprecated    bool                 // Indicate that a warning is output when an Option is used.
+	IsDuration      bool                 // Indicate that an Option's arguments are durations, such as "1h30m".
+	IsHidden        bool                 // Indicate that an Option is omitted from help text, while still being parsed.
+	IsNegatable     bool                 // Indicate that an Option's long names can be prefixed with `no-` to store false.
+	IsRemainder     bool                 // Indicate that a positional Option captures all remaining arguments verbatim.
+	IsRequired      bool                 // Indicate if an Option must be present when parsing.
+	IsPositional    bool                 // Indicate that an Option is identified by its position when parsing.
+	IsValueOptional bool                 // Indicate that an Option's value must be attached, otherwise storing its constant value.
+	MaxCount        int                  // The number of times an Option can be present when parsing, or 0 for any number.
+	MetaVarText     []string             // Text used when representing an Option and its arguments.
+	MinCount        int                  // The number of times an Option must be present when parsing.
+	PublicNames     []string             // Qualifiers for identifying the option during parsing.
+	ValidChoices    []string             // A slice of valid choices for arguments of the Option.
+	Validators      []func(string) error // Callbacks which return an error for invalid arguments of the Option.
 }
 
 // Action sets the option's action to the provided action function.
@@ -120,6 +342,16 @@ func (f *Option) Action(action Action) *Option {
 	return f
 }
 
+// Alias adds the provided names as aliases of the option, which are accepted in
+// place of its other names when parsing, but omitted from help text, usage, and
+// shell completions. This allows an option to be renamed, such as from `--out`
+// to `--output`, without breaking existing invocations.
+func (f *Option) Alias(names ...string) *Option {
+	f.PublicNames = append(f.PublicNames, names...)
+	f.AliasNames = append(f.AliasNames, names...)
+	return f
+}
+
 // Choices appends the provided slice as acceptable arguments for the option.
 func (f *Option) Choices(choices ...string) *Option {
 	f.ValidChoices = []string{}
@@ -129,6 +361,16 @@ func (f *Option) Choices(choices ...string) *Option {
 	return f
 }
 
+// CompleteWith sets the function returning the candidates for completing the
+// option's argument within a shell, such as the names of branches, which is
+// called with the portion of the argument already typed. The generated
+// completion scripts invoke the program to run it, which takes precedence over
+// the option's choices.
+func (f *Option) CompleteWith(fn CompleteFunc) *Option {
+	f.Completer = fn
+	return f
+}
+
 // Const sets the option's constant value to the provided interface. A option's constant value
 // is only used for certain actions. By default, the constant value is `nil`.
 func (f *Option) Const(value string) *Option {
@@ -143,6 +385,41 @@ func (f *Option) Default(value string) *Option {
 	return f
 }
 
+// defaultMetaVar returns the text representing the option's arguments when no
+// meta variable is set, which is the option's first long name, or otherwise its
+// destination name, with any hyphens replaced by underscores.
+func (f *Option) defaultMetaVar() string {
+	for _, name := range f.PublicNames {
+		if len(name) > 1 {
+			return strings.Replace(name, "-", "_", -1)
+		}
+	}
+	return strings.Replace(f.DestName, "-", "_", -1)
+}
+
+// Deprecated marks the option as deprecated, outputting a warning containing the
+// provided message the first time the option is used when parsing. Deprecated
+// options are also hidden from the parser's help text.
+func (f *Option) Deprecated(message string) *Option {
+	f.IsDeprecated = true
+	f.DeprecatedText = message
+	return f.Hidden()
+}
+
+// DeprecatedAlias adds the provided names as aliases of the option, as with
+// Alias, outputting a warning containing the provided message the first time an
+// alias is used when parsing. Without a message, the warning names the option's
+// first long name as the replacement.
+func (f *Option) DeprecatedAlias(message string, names ...string) *Option {
+	if f.AliasWarnings == nil {
+		f.AliasWarnings = make(map[string]string)
+	}
+	for _, name := range names {
+		f.AliasWarnings[name] = message
+	}
+	return f.Alias(names...)
+}
+
 // Dest sets a option's destination name. This is used as the key for storing the option's
 // values within the parser.
 func (f *Option) Dest(name string) *Option {
@@ -169,12 +446,30 @@ func (f *Option) DisplayName() string {
 
 	var names []string
 	for _, name := range f.PublicNames {
-		names = append(names, getDisplayName(name))
+		if f.isAlias(name) == false {
+			names = append(names, getDisplayName(name))
+		}
 	}
 
 	return strings.Join(names, ", ")
 }
 
+// Duration sets the option to expect its arguments to be durations, as accepted
+// by time.ParseDuration, such as "30s" or "1h30m".
+func (f *Option) Duration() *Option {
+	f.IsDuration = true
+	return f
+}
+
+// FromEnv sets the name of an environment variable to be used as the option's
+// value when the option is not present while parsing. An environment variable
+// which is set to an empty string is treated as if it were not set. The name
+// takes precedence over one derived from the parser's environment prefix.
+func (f *Option) FromEnv(name string) *Option {
+	f.EnvVar = name
+	return f
+}
+
 // GetChoices returns a string-representation of the valid chocies for the
 // current Option.
 func (f *Option) GetChoices() string {
@@ -188,11 +483,29 @@ func (f *Option) GetChoices() string {
 	return join("", "{", strings.Join(choices, ","), "}")
 }
 
+// GetHelpText returns the option's help text. When the option has valid choices,
+// they are listed after the help text.
+func (f *Option) GetHelpText() string {
+	if len(f.ValidChoices) == 0 {
+		return f.HelpText
+	}
+
+	choices := join("", "(choose from: ", strings.Join(f.ValidChoices, ", "), ")")
+	if len(f.HelpText) == 0 {
+		return choices
+	}
+	return join(" ", f.HelpText, choices)
+}
+
 // GetUsage returns the usage text for the option. This includes proper formatting
 // of the option's display name & parameters. For parameters: by default, parameters
 // will be the option's public name. This can be overridden by modifying the MetaVars
 // slice for the option.
 func (f *Option) GetUsage() string {
+	if f.IsPositional == true {
+		return f.getPositionalUsage()
+	}
+
 	var usage []string
 
 	isRequired := f.IsRequired
@@ -203,18 +516,39 @@ func (f *Option) GetUsage() string {
 	if len(f.PublicNames) == 1 {
 		usage = append(usage, f.DisplayName())
 	} else {
-		pNames := f.PublicNames
-		f.PublicNames = []string{f.PublicNames[0]}
-		usage = append(usage, f.DisplayName())
-		f.PublicNames = pNames
+		// Prefer the option's short name, when it has one.
+		name := f.PublicNames[0]
+		for _, publicName := range f.PublicNames {
+			if len(publicName) == 1 {
+				name = publicName
+				break
+			}
+		}
+
+		usage = append(usage, prefixedName(strings.ToLower(name)))
 	}
 
+	usage = append(usage, f.getArgsUsage())
+
+	if isRequired == false {
+		usage = append(usage, "]")
+	}
+
+	return join("", usage...)
+}
+
+// getArgsUsage returns the usage of the option's arguments, which follows the
+// option's name, such as ` FILE` or ` DIR [DIR ...]`. An empty string is returned
+// for options which do not expect any arguments.
+func (f *Option) getArgsUsage() string {
+	var usage []string
 	var nargs []string
+	metaVars := f.MetaVarText
 	choices := f.GetChoices()
-	if len(choices) == 0 && len(f.MetaVarText) == 0 {
-		f.MetaVarText = []string{f.DestName}
-	} else if len(f.MetaVarText) == 0 {
-		f.MetaVarText = []string{choices}
+	if len(choices) == 0 && len(metaVars) == 0 {
+		metaVars = []string{f.defaultMetaVar()}
+	} else if len(metaVars) == 0 {
+		metaVars = []string{choices}
 	}
 
 	if strings.ContainsAny(f.ArgNum, "?*+rR") == false {
@@ -224,19 +558,21 @@ func (f *Option) GetUsage() string {
 			panic(err)
 		}
 
-		metaLen := len(f.MetaVarText)
+		metaLen := len(metaVars)
 
 		for count < max {
 			meta := ""
 			if count >= metaLen {
-				meta = f.MetaVarText[metaLen-1]
+				meta = metaVars[metaLen-1]
 			} else {
-				meta = f.MetaVarText[count]
+				meta = metaVars[count]
 			}
 			nargs = append(nargs, strings.ToUpper(meta))
 			count++
 		}
-		if len(nargs) > 0 {
+		if f.IsValueOptional == true && len(nargs) > 0 {
+			usage = append(usage, "[=", nargs[0], "]")
+		} else if len(nargs) > 0 {
 			usage = append(usage, " ", join(" ", nargs...))
 		}
 	} else {
@@ -245,7 +581,7 @@ func (f *Option) GetUsage() string {
 			usage = append(
 				usage,
 				" [",
-				strings.ToUpper(f.MetaVarText[0]),
+				strings.ToUpper(metaVars[0]),
 				"]",
 			)
 		case "r":
@@ -260,13 +596,13 @@ func (f *Option) GetUsage() string {
 			fallthrough
 		case "*":
 			first := f.DestName
-			if len(f.MetaVarText) > 0 {
-				first = f.MetaVarText[0]
+			if len(metaVars) > 0 {
+				first = metaVars[0]
 			}
 			second := first
 
-			if len(f.MetaVarText) > 1 {
-				second = f.MetaVarText[1]
+			if len(metaVars) > 1 {
+				second = metaVars[1]
 			}
 
 			before := ""
@@ -289,11 +625,148 @@ func (f *Option) GetUsage() string {
 		}
 	}
 
-	if isRequired == false {
-		usage = append(usage, "]")
+	return join("", usage...)
+}
+
+// getHelpName returns the name of the option as listed within the help text.
+// Positional options are listed by their usage, while other options are listed
+// by their display name, followed by the usage of their arguments. The long
+// names of negatable flags are listed with a `[no-]` hint, such as `--[no-]color`.
+func (f *Option) getHelpName() string {
+	if f.IsPositional == true {
+		return f.GetUsage()
+	} else if f.IsNegatable == false || f.ArgNum != "0" {
+		return f.DisplayName() + f.getArgsUsage()
 	}
 
-	return join("", usage...)
+	var names []string
+	for _, name := range f.PublicNames {
+		if f.isAlias(name) == true {
+			continue
+		} else if len(name) > 1 {
+			names = append(names, "--[no-]"+strings.ToLower(name))
+		} else {
+			names = append(names, prefixedName(strings.ToLower(name)))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// getFlagValue returns the boolean value stored by the option's action, and true,
+// if the option is a flag using the StoreTrue or StoreFalse action. Otherwise,
+// false is returned.
+func (f *Option) getFlagValue() (bool, bool) {
+	if f.ArgNum != "0" || f.DesiredAction == nil {
+		return false, false
+	}
+
+	action := reflect.ValueOf(f.DesiredAction).Pointer()
+	if action == reflect.ValueOf(StoreTrue).Pointer() {
+		return true, true
+	} else if action == reflect.ValueOf(StoreFalse).Pointer() {
+		return false, true
+	}
+	return false, false
+}
+
+// getTypedValue returns the provided value stored for the option, converted to
+// the option's type. Strings are converted to a bool for flags, an int for
+// counters, or to the option's expected type. Slices have each element converted
+// to the option's expected type, and files are represented by their name. A value
+// which cannot be converted is returned unmodified.
+func (f *Option) getTypedValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if _, ok := f.getFlagValue(); ok == true || (f.IsNegatable == true && f.ArgNum == "0") {
+			if b, err := parseBool(v); err == nil {
+				return b
+			}
+		} else if f.DesiredAction != nil && reflect.ValueOf(f.DesiredAction).Pointer() == reflect.ValueOf(Count).Pointer() {
+			if i, err := strconv.Atoi(v); err == nil {
+				return i
+			}
+		}
+		return typedString(f.ExpectedType, v)
+	case []string:
+		if f.ExpectedType == reflect.Invalid || f.ExpectedType == reflect.String {
+			return append([]string{}, v...)
+		}
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = typedString(f.ExpectedType, s)
+		}
+		return values
+	case *os.File:
+		return v.Name()
+	}
+	return value
+}
+
+// getEnvName returns the name of the option's environment variable. Without one
+// set by FromEnv, the name is derived from the provided prefix and the option's
+// first long name, uppercased with hyphens replaced by underscores, such as
+// `MYAPP_MAX_RETRIES` for `--max-retries`. An empty string is returned for
+// options which do not take their value from the environment.
+func (f *Option) getEnvName(prefix string) string {
+	if f.IgnoreEnv == true {
+		return ""
+	} else if len(f.EnvVar) > 0 {
+		return f.EnvVar
+	} else if len(prefix) == 0 || f.IsPositional == true {
+		return ""
+	}
+
+	for _, name := range f.PublicNames {
+		if len(name) > 1 {
+			return strings.ToUpper(join("_", prefix, strings.Replace(name, "-", "_", -1)))
+		}
+	}
+	return ""
+}
+
+// getEnvValue returns the value of the option's environment variable, using the
+// provided prefix to derive its name, and true if that variable is set to a
+// non-empty value.
+func (f *Option) getEnvValue(prefix string) (string, bool) {
+	name := f.getEnvName(prefix)
+	if len(name) == 0 {
+		return "", false
+	}
+
+	value := os.Getenv(name)
+	return value, len(value) > 0
+}
+
+// getPositionalUsage returns the usage text for a positional option, using its
+// destination name as a placeholder. Each required argument is rendered as
+// `<name>`, while optional arguments are rendered as `[name]` or `[name...]`.
+func (f *Option) getPositionalUsage() string {
+	name := f.DestName
+	if len(f.MetaVarText) > 0 {
+		name = f.MetaVarText[0]
+	} else if len(name) == 0 && len(f.PublicNames) > 0 {
+		name = f.PublicNames[0]
+	}
+
+	switch f.ArgNum {
+	case "?":
+		return join("", "[", name, "]")
+	case "*", "r", "R":
+		return join("", "[", name, "...]")
+	case "+":
+		return join("", "<", name, ">...")
+	}
+
+	num, err := strconv.Atoi(f.ArgNum)
+	if err != nil {
+		panic(err)
+	}
+
+	var usage []string
+	for count := 0; count < num; count++ {
+		usage = append(usage, join("", "<", name, ">"))
+	}
+	return join(" ", usage...)
 }
 
 // Help sets the option's help/usage text.
@@ -302,6 +775,31 @@ func (f *Option) Help(text string) *Option {
 	return f
 }
 
+// Hidden omits the option from the parser's help text, usage, and completion
+// scripts, while it is still parsed as usual. Hidden options are never suggested
+// for mistyped options.
+func (f *Option) Hidden() *Option {
+	f.IsHidden = true
+	return f
+}
+
+// IgnoreChoiceCase enables the option's arguments to match its valid choices
+// case-insensitively.
+func (f *Option) IgnoreChoiceCase() *Option {
+	f.IgnoreCase = true
+	return f
+}
+
+// isAlias returns true if the provided name is one of the option's aliases.
+func (f *Option) isAlias(name string) bool {
+	for _, alias := range f.AliasNames {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *Option) IsPublicName(name string) bool {
 	for _, opName := range f.PublicNames {
 		if name == opName {
@@ -311,9 +809,19 @@ func (f *Option) IsPublicName(name string) bool {
 	return false
 }
 
+// MaxOccurrences sets the number of times the option can be present when
+// parsing, such as to accept a repeatable `-v` at most three times. Parsing
+// fails when the option is present more often.
+func (f *Option) MaxOccurrences(n int) *Option {
+	f.MaxCount = n
+	return f
+}
+
 // MetaVar sets the option's metavar text to the provided string. Additional
 // metavar strings can be provided, and will be used for options with more than
-// expected argument.
+// expected argument. The metavar names the option's arguments in both its usage
+// and help text, such as `--output FILE`; by default, the option's long name is
+// used.
 func (f *Option) MetaVar(meta string, metaSlice ...string) *Option {
 	s := []string{meta}
 	for _, text := range metaSlice {
@@ -324,6 +832,15 @@ func (f *Option) MetaVar(meta string, metaSlice ...string) *Option {
 	return f
 }
 
+// MinOccurrences sets the number of times the option must be present when
+// parsing, such as to require at least one `--tag` for an option appending its
+// values. Parsing fails when the option is present less often, regardless of
+// any value taken from its environment variable, the config file, or its default.
+func (f *Option) MinOccurrences(n int) *Option {
+	f.MinCount = n
+	return f
+}
+
 // Nargs sets the option's number of expected arguments. Integers represent
 // the absolute number of arguments to be expected. The `?` character represents
 // an expection of zero or one arguments. The `*` character represents an expectation
@@ -358,6 +875,33 @@ func (f *Option) Nargs(nargs interface{}) *Option {
 	return f
 }
 
+// Negatable enables the option's long names to be prefixed with `no-` when
+// parsing, such as `--no-color` for `--color`, to store false.
+func (f *Option) Negatable() *Option {
+	f.IsNegatable = true
+	return f
+}
+
+// NotFromEnv prevents the option from taking its value from an environment
+// variable, including one derived from the parser's environment prefix.
+func (f *Option) NotFromEnv() *Option {
+	f.IgnoreEnv = true
+	return f
+}
+
+// NotHidden includes the option within the parser's help text.
+func (f *Option) NotHidden() *Option {
+	f.IsHidden = false
+	return f
+}
+
+// NotNegatable prevents the option's long names from being prefixed with `no-`
+// when parsing arguments.
+func (f *Option) NotNegatable() *Option {
+	f.IsNegatable = false
+	return f
+}
+
 // NotRequired prevents the option from being required to be present when parsing
 // arguments.
 func (f *Option) NotRequired() *Option {
@@ -371,6 +915,18 @@ func (f *Option) NotPositional() *Option {
 	return f
 }
 
+// OptionalValue makes the option's value optional, setting its Nargs to 1. A
+// value is only bound to the option when attached to it, such as `--color=always`.
+// Otherwise, such as for a bare `--color`, the option's constant value is used as
+// its implicit value, and the following argument is never consumed.
+//
+//		o := argparse.NewOption("color", "color", "Colorize output").Action(argparse.Store)
+//		o.OptionalValue().Const("auto").Default("never")
+func (f *Option) OptionalValue() *Option {
+	f.IsValueOptional = true
+	return f.Nargs("1")
+}
+
 // Positional enables a option to be positionally interpretted.
 func (f *Option) Positional() *Option {
 	f.IsPositional = true
@@ -388,6 +944,23 @@ func (f *Option) String() string {
 	return join(" ", f.GetUsage(), f.HelpText)
 }
 
+// Validate appends the provided callback to the option's validators. Each of the
+// option's arguments are passed to its validators, in order, and any error
+// returned will prevent further parsing.
+func (f *Option) Validate(validator func(string) error) *Option {
+	f.Validators = append(f.Validators, validator)
+	return f
+}
+
+// ValidateContext appends the provided callback to the option's validators, as
+// with Validate, while also providing it the context given to ParseContext, or
+// otherwise context.Background(). These validators are run after those added by
+// Validate.
+func (f *Option) ValidateContext(validator ContextValidator) *Option {
+	f.CtxValidators = append(f.CtxValidators, validator)
+	return f
+}
+
 // Type sets the expected reflect.Kind type an option will accept.
 func (f *Option) Type(kind reflect.Kind) *Option {
 	invalidKinds := []reflect.Kind{
@@ -1,7 +1,9 @@
 package argparse
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +31,63 @@ func TestValidateChoice(t *testing.T) {
 	}
 }
 
+// TestValidateChoice_IgnoreCase ensures that choices are matched case-sensitively
+// by default, and case-insensitively when enabled.
+func TestValidateChoice_IgnoreCase(t *testing.T) {
+	f := NewOption("l level", "level", "log level").Choices("debug", "info", "warn", "error")
+	if ValidateChoice(*f, "INFO") == nil {
+		t.Error("An error was expected but not provided")
+	}
+
+	f.IgnoreChoiceCase()
+	if ValidateChoice(*f, "INFO") != nil {
+		t.Error("No error was expected")
+	}
+	if ValidateChoice(*f, "trace") == nil {
+		t.Error("An error was expected but not provided")
+	}
+}
+
+// TestValidateCustom ensures that every validator of an option is run, in order,
+// and that the first error returned is wrapped with the option's name.
+func TestValidateCustom(t *testing.T) {
+	var calls []string
+	positive := func(arg string) error {
+		calls = append(calls, "positive")
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("must be positive")
+		}
+		return nil
+	}
+	even := func(arg string) error {
+		calls = append(calls, "even")
+		if arg != "2" && arg != "4" {
+			return fmt.Errorf("must be even")
+		}
+		return nil
+	}
+
+	f := NewOption("n", "num", "a number").Validate(positive).Validate(even)
+	if err := ValidateCustom(*f, "4"); err != nil {
+		t.Errorf("No error was expected, but received: %s", err.Error())
+	}
+	if len(calls) != 2 || calls[0] != "positive" || calls[1] != "even" {
+		t.Errorf("Validators were not called in order: %v", calls)
+	}
+
+	err := ValidateCustom(*f, "-3")
+	expected := `-n: invalid value "-3": must be positive`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	p := NewParser("parser")
+	p.AddOption(NewOption("n", "num", "a number").Nargs("1").Action(Store).Validate(even))
+	if _, _, err := p.Parse("-n", "3"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+}
+
 // TestValidateType will test the ValidateType function to ensure it will raise
 // errors when an incorrect type is provided, and nil in all other cases.
 func TestValidateType(t *testing.T) {
@@ -182,6 +241,38 @@ func TestOptionDisplayName(t *testing.T) {
 	}
 }
 
+// TestOptionFromEnv tests that an option's EnvVar is updated to the provided
+// value via the FromEnv method.
+func TestOptionFromEnv(t *testing.T) {
+	f := Option{}
+
+	if f.EnvVar != "" {
+		t.Error("Option EnvVar should be empty upon initialization")
+	}
+
+	expected := "MY_VAR"
+	f.FromEnv(expected)
+
+	if f.EnvVar != expected {
+		t.Errorf("Option EnvVar is '%s', but was expected to be: '%s'", f.EnvVar, expected)
+	}
+}
+
+// TestOptionGetHelpText tests that an option's help text includes its valid
+// choices, if any.
+func TestOptionGetHelpText(t *testing.T) {
+	f := NewOption("l level", "level", "Log level")
+	if f.GetHelpText() != "Log level" {
+		t.Errorf("Unexpected help text: '%s'", f.GetHelpText())
+	}
+
+	expected := "Log level (choose from: debug, info, warn, error)"
+	f.Choices("debug", "info", "warn", "error")
+	if f.GetHelpText() != expected {
+		t.Errorf("Expected help text '%s', but received: '%s'", expected, f.GetHelpText())
+	}
+}
+
 // TestOptionGetUsage tests the retrival of a option's usage string via the GetUsage method.
 func TestOptionGetUsage(t *testing.T) {
 	f := NewOption("foobar", "foobar dest", "Activate a foobar boolean")
@@ -191,6 +282,48 @@ func TestOptionGetUsage(t *testing.T) {
 	}
 }
 
+// TestOptionGetUsage_DefaultMetaVar tests that an option's default metavar is
+// derived from its long name, with hyphens replaced by underscores, consistently
+// within its usage and help text, while an explicit metavar is left unmodified.
+func TestOptionGetUsage_DefaultMetaVar(t *testing.T) {
+	f := NewOption("u user-name", "user", "user to log in as").Nargs("1").Action(Store)
+	if usage := f.GetUsage(); strings.Contains(usage, "USER_NAME") == false {
+		t.Errorf("Expected usage to contain 'USER_NAME', but received: '%s'", usage)
+	}
+	if name := f.getHelpName(); strings.HasSuffix(name, "--user-name USER_NAME") == false {
+		t.Errorf("Expected help name ending with '--user-name USER_NAME', but received: '%s'", name)
+	}
+
+	f.MetaVar("user-name")
+	if usage := f.GetUsage(); strings.Contains(usage, "USER-NAME") == false {
+		t.Errorf("Expected usage to contain 'USER-NAME', but received: '%s'", usage)
+	}
+}
+
+// TestOptionGetUsage_Positional tests the retrival of a positional option's usage
+// string via the GetUsage method, for each type of nargs.
+func TestOptionGetUsage_Positional(t *testing.T) {
+	tests := map[string]string{
+		"1": "<src>",
+		"2": "<src> <src>",
+		"?": "[src]",
+		"*": "[src...]",
+		"+": "<src>...",
+	}
+
+	for nargs, expected := range tests {
+		f := NewArg("s source", "src", "source file").Nargs(nargs)
+		if usage := f.GetUsage(); usage != expected {
+			t.Errorf("Expected usage '%s' for nargs '%s', but received: '%s'", expected, nargs, usage)
+		}
+	}
+
+	f := NewArg("src", "src", "source file").MetaVar("path")
+	if usage := f.GetUsage(); usage != "<path>" {
+		t.Errorf("Expected usage '<path>', but received: '%s'", usage)
+	}
+}
+
 // TestOptionHelp tests that a option's HelpText is updated to the provided value
 // via the Help method.
 func TestOptionHelp(t *testing.T) {
@@ -245,6 +378,54 @@ func TestOptionNargs(t *testing.T) {
 	}
 }
 
+// TestOptionHidden tests that a option's IsHidden boolean is updated to become
+// 'true' when the Hidden method is called, and 'false' when NotHidden is called.
+func TestOptionHidden(t *testing.T) {
+	f := Option{}
+
+	if f.IsHidden != false {
+		t.Error("Option IsHidden should be false upon initialization")
+	}
+
+	if f.Hidden(); f.IsHidden != true {
+		t.Errorf("Option IsHidden is '%t', but was expected to be: '%t'", f.IsHidden, true)
+	}
+
+	if f.NotHidden(); f.IsHidden != false {
+		t.Errorf("Option IsHidden is '%t', but was expected to be: '%t'", f.IsHidden, false)
+	}
+}
+
+// TestOptionOptionalValue tests that a option's IsValueOptional boolean is updated
+// to become 'true' when the OptionalValue method is called, expecting one argument.
+func TestOptionOptionalValue(t *testing.T) {
+	f := NewOption("color", "color", "colorize output").OptionalValue()
+
+	if f.IsValueOptional != true {
+		t.Errorf("Option IsValueOptional is '%t', but was expected to be: '%t'", f.IsValueOptional, true)
+	}
+
+	if f.ArgNum != "1" {
+		t.Errorf("Option ArgNum is '%s', but was expected to be: '%s'", f.ArgNum, "1")
+	}
+}
+
+// TestOptionNotNegatable tests that a option's IsNegatable boolean is updated to
+// become 'false' when the NotNegatable method is called.
+func TestOptionNotNegatable(t *testing.T) {
+	f := NewFlag("color", "color", "colorize output")
+
+	if f.IsNegatable != true {
+		t.Error("Flag IsNegatable should be true upon initialization")
+	}
+
+	f.NotNegatable()
+
+	if f.IsNegatable != false {
+		t.Errorf("Option IsNegatable is '%t', but was expected to be: '%t'", f.IsNegatable, false)
+	}
+}
+
 // TestOptionNotRequired tests that a option's IsRequired boolean is updated to
 // become 'false' when the Required method is called.
 func TestOptionNotRequired(t *testing.T) {
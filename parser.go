@@ -1,28 +1,88 @@
 package argparse
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Parser contains program-level settings and information, stores options,
 // and values collected upon parsing.
 type Parser struct {
-	ProgramName string
-	AllowAbbrev bool
-	Options     []*Option
-	UsageText   string
-	VersionDesc string
-	Namespace   *Namespace
+	ProgramName          string
+	CommandName          string
+	AllowAbbrev          bool
+	CaseInsensitive      bool
+	ShortCaseInsensitive bool
+	SingleDashLong       bool
+	SlashPrefix          bool
+	NoInterspersed       bool
+	PlusFlags            bool
+	HelpDisabled         bool
+	Separators           string
+	StopEarly            bool
+	Width                int
+	Color                ColorMode
+	HeaderColor          string
+	MaxHelpWidth         int
+	HelpColumn           int
+	EnvPrefix            string
+	HelpSort             HelpSort
+	IgnoreUnknown        bool
+	Options              []*Option
+	Commands             []*Parser
+	UsageText            string
+	EpilogText           string
+	VersionDesc          string
+	Namespace            *Namespace
+	WarningOutput        io.Writer
+	Output               io.Writer
+	ErrorOutput          io.Writer
+	ExitFunc             func(code int)
+
+	helpOption      *Option
+	defaultHelp     bool
+	exclusiveGroups [][]string
+	requiredGroups  [][]string
+	helpGroups      []helpGroup
+	offset          int
+	files           []*os.File
+	config          map[string]interface{}
+	supplied        map[*Option]bool
+	leftovers       []string
+	unknown         []string
+	bindings        []binding
+	ctx             context.Context
+}
+
+// helpGroup contains options which are listed together under a header within
+// the help text.
+type helpGroup struct {
+	title   string
+	options []*Option
 }
 
+// HelpSort determines the order in which options are listed within each section
+// of the help text.
+type HelpSort int
+
+const (
+	SortDeclared     HelpSort = iota // List options in the order they were added.
+	SortAlphabetical                 // List options by their long names, case-insensitively.
+)
+
 // AddHelp adds a new option to output usage information for the current parser
 // and each of its options.
 func (p *Parser) AddHelp() *Parser {
 	helpOption := NewOption("h help", "help", "Show program help").Action(ShowHelp)
 
-	p.Options = append(p.Options, helpOption)
+	p.AddOption(helpOption)
+	p.helpOption = helpOption
 	return p
 }
 
@@ -30,21 +90,95 @@ func (p *Parser) AddHelp() *Parser {
 func (p *Parser) AddVersion() *Parser {
 	versionOption := NewOption("v version", "version", "Show program version").Action(ShowVersion)
 
-	p.Options = append(p.Options, versionOption)
-	return p
+	return p.AddOption(versionOption)
+}
+
+// AddCommand creates a new parser for a subcommand with the provided name and
+// help text, and adds it to the current parser. The returned parser is used to
+// define the subcommand's own options.
+func (p *Parser) AddCommand(name, help string) *Parser {
+	command := &Parser{
+		CommandName:          name,
+		UsageText:            help,
+		AllowAbbrev:          p.AllowAbbrev,
+		CaseInsensitive:      p.CaseInsensitive,
+		ShortCaseInsensitive: p.ShortCaseInsensitive,
+		SingleDashLong:       p.SingleDashLong,
+		SlashPrefix:          p.SlashPrefix,
+		NoInterspersed:       p.NoInterspersed,
+		PlusFlags:            p.PlusFlags,
+		Separators:           p.Separators,
+		Width:                p.Width,
+		Color:                p.Color,
+		HeaderColor:          p.HeaderColor,
+		MaxHelpWidth:         p.MaxHelpWidth,
+		HelpColumn:           p.HelpColumn,
+		EnvPrefix:            p.EnvPrefix,
+		HelpSort:             p.HelpSort,
+		IgnoreUnknown:        p.IgnoreUnknown,
+		Output:               p.Output,
+		ErrorOutput:          p.ErrorOutput,
+		WarningOutput:        p.WarningOutput,
+		config:               p.config,
+	}
+	command.Prog(join(" ", p.ProgramName, name))
+
+	p.Commands = append(p.Commands, command)
+	return command
 }
 
-// AddOption appends the provided option to the current parser.
+// AddOption appends the provided option to the current parser. As registering
+// the same public name for multiple options is a programming error, AddOption
+// panics if a public name of the option is already used by another option.
 func (p *Parser) AddOption(f *Option) *Parser {
+	p.checkOptionNames(f)
+
 	p.Options = append(p.Options, f)
 	return p
 }
 
-// AddOptions appends the provided options to the current parser.
+// AddOptions appends the provided options to the current parser, panicking if
+// any of their public names are already used, as described by AddOption.
 func (p *Parser) AddOptions(opts ...*Option) *Parser {
 	for _, opt := range opts {
-		p.Options = append(p.Options, opt)
+		p.AddOption(opt)
+	}
+	return p
+}
+
+// Context returns the context provided to ParseContext while parsing, for use
+// by actions, or otherwise context.Background().
+func (p *Parser) Context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
 	}
+	return p.ctx
+}
+
+// CloseFiles closes each file opened by an OpenFile action while parsing, other
+// than stdin and stdout. The first error encountered while closing the files is
+// returned, once every file has been closed.
+func (p *Parser) CloseFiles() error {
+	var first error
+	for _, file := range p.files {
+		if err := file.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.files = nil
+
+	for _, command := range p.Commands {
+		if err := command.CloseFiles(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// DisableHelpFlag prevents the parser from automatically adding the `-h` and
+// `--help` options when parsing or generating help text.
+func (p *Parser) DisableHelpFlag() *Parser {
+	p.HelpDisabled = true
 	return p
 }
 
@@ -63,134 +197,48 @@ func (p *Parser) GetOption(name string) (*Option, error) {
 	return nil, InvalidFlagNameErr{name}
 }
 
+// FormatError returns the provided error formatted as `program: error: message`,
+// followed by a single line describing the parser's usage.
+func (p *Parser) FormatError(err error) string {
+	return join("\n", join(": ", p.ProgramName, "error", err.Error()), p.GetUsage())
+}
+
 // GetHelp returns a string containing the parser's description text,
 // and the usage information for each option currently incorperated within
-// the parser.
+// the parser. The help text is colorized only when the parser's color mode is
+// ColorAlways.
 func (p *Parser) GetHelp() string {
-	// Get screen width to determine max line lengths later.
-	screenWidth := getScreenWidth()
-
-	var positional []*Option
-	var notPositional []*Option
-	var usage []string
-
-	header := []string{"usage:", p.ProgramName}
-	headerIndent := len(join(" ", header...))
-	headerLen := headerIndent
-
-	var notPosArgs []string
-	var posArgs []string
-	longest := 0
-
-	for _, arg := range p.Options {
-		//if arg.IsPositional == false {
-		if arg.IsPositional == false {
-			notPositional = append(notPositional, arg)
-		} else {
-			positional = append(positional, arg)
-		}
-	}
+	return p.getHelp(p.Color == ColorAlways)
+}
 
-	for _, arg := range notPositional {
-		displayName := arg.DisplayName()
-		if len(displayName) > longest {
-			longest = len(displayName)
-		}
+// GetUsage returns the one-line synopsis of the parser, such as
+// `usage: prog [-v] [--out OUT] <src> <dst>`, describing its options, positional
+// options, and commands in the order they are shown by GetHelp. Options which
+// are not required are enclosed within brackets.
+func (p *Parser) GetUsage() string {
+	p.addDefaultHelp()
 
-		argUsg := arg.GetUsage()
-		notPosArgs = append(notPosArgs, arg.GetUsage())
-		headerLen = headerLen + len(argUsg)
-		if headerLen+len(argUsg) > screenWidth {
-			headerLen = headerIndent
-			notPosArgs = append(notPosArgs, join("", "\n", spacer(headerIndent)))
+	usage := []string{"usage:", p.ProgramName}
+	for _, option := range p.Options {
+		if option.IsPositional == false && option.IsHidden == false {
+			usage = append(usage, option.GetUsage())
 		}
 	}
-
-	for _, arg := range positional {
-		displayName := arg.DisplayName()
-		if len(displayName) > longest {
-			longest = len(displayName)
-		}
-
-		argUsg := arg.GetUsage()
-		posArgs = append(posArgs, arg.GetUsage())
-		headerLen = headerLen + len(argUsg)
-		if headerLen+len(argUsg) > screenWidth {
-			headerLen = headerIndent
-			posArgs = append(posArgs, join("", "\n", spacer(headerIndent)))
+	for _, option := range p.Options {
+		if option.IsPositional == true && option.IsHidden == false {
+			usage = append(usage, option.GetUsage())
 		}
 	}
 
-	longest = longest + 4
-
-	header = append(header, notPosArgs...)
-	header = append(header, posArgs...)
-
-	usage = append(usage, join(" ", header...), "\n")
-
-	if len(p.UsageText) > 0 {
-		usage = append(usage, "\n", p.UsageText, "\n")
-	}
-
-	if len(positional) > 0 {
-		usage = append(usage, "\n", "positional arguments:", "\n")
-		var names []string
-		var help []string
-
-		for _, arg := range positional {
-			names = append(names, arg.GetUsage())
-			help = append(help, arg.HelpText)
-		}
-
-		var lines []string
-		for i, name := range names {
-			lines = append(lines, "  ", name)
-			lines = append(lines, spacer(longest-len(name)-2))
-			if longest > screenWidth {
-				lines = append(lines, "\n", spacer(longest))
-			}
-
-			helpLines := wordWrap(help[i], screenWidth-longest)
-			lines = append(lines, helpLines[0], "\n")
-			if len(helpLines) > 1 {
-				for _, helpLine := range helpLines[1:len(helpLines)] {
-					lines = append(lines, spacer(longest), helpLine, "\n")
-				}
-			}
-		}
-		usage = append(usage, lines...)
+	var commandNames []string
+	for _, command := range p.Commands {
+		commandNames = append(commandNames, command.CommandName)
 	}
-
-	if len(notPositional) > 0 {
-		usage = append(usage, "\n", "optional arguments:", "\n")
-		var names []string
-		var help []string
-
-		for _, arg := range notPositional {
-			names = append(names, arg.DisplayName())
-			help = append(help, arg.HelpText)
-		}
-
-		var lines []string
-		for i, name := range names {
-			lines = append(lines, "  ", name)
-			lines = append(lines, spacer(longest-len(name)-2))
-			if longest > screenWidth {
-				lines = append(lines, "\n", spacer(longest))
-			}
-
-			helpLines := wordWrap(help[i], screenWidth-longest)
-			lines = append(lines, helpLines[0], "\n")
-			if len(helpLines) > 1 {
-				for _, helpLine := range helpLines[1:len(helpLines)] {
-					lines = append(lines, spacer(longest), helpLine, "\n")
-				}
-			}
-		}
-		usage = append(usage, lines...)
+	if len(commandNames) > 0 {
+		usage = append(usage, join("", "{", join(",", commandNames...), "}"), "...")
 	}
 
-	return join("", usage...)
+	return join(" ", usage...)
 }
 
 // GetVersion will return the version text for the current parser.
@@ -200,90 +248,92 @@ func (p *Parser) GetVersion() string {
 
 // Parser accepts a slice of strings as options and arguments to be parsed. The
 // parser will call each encountered option's action. Unexpected options will
-// cause an error. All errors are returned.
+// cause an error. All errors are returned; when more than one error occurs, they
+// are returned together as ParseErrors. Values from any previous parse are
+// discarded, so the same parser can parse multiple sets of arguments.
+//
+// An argument of the form `@file` is replaced by the arguments read from that
+// file, which are separated by whitespace and may be quoted. A leading `@@`
+// escapes an argument which should begin with a literal `@` instead. Errors for
+// invalid options identify the argument containing the option by its index
+// within the arguments, once any response files have been expanded.
+//
+// When the first argument is `__complete`, as passed by the generated shell
+// completion scripts, the candidates for completing the last argument are output
+// instead, and a ShowCompletionErr is returned.
 func (p *Parser) Parse(allArgs ...string) (*Namespace, []string, error) {
-	if p.Namespace == nil {
-		p.Namespace = NewNamespace()
-	}
-
-	requiredOptions := make(map[string]*Option)
-	remainderOptions := make(map[string]*Option)
-	var err error
-
-	var optionListing []*Option
+	p.Reset()
+	p.offset = 0
 
-	for _, option := range p.Options {
-		if option.IsRequired == true {
-			requiredOptions[option.DisplayName()] = option
-		}
-		p.Namespace.Set(option.DestName, option.DefaultVal)
-		if strings.ToLower(option.ArgNum) == "r" {
-			remainderOptions[option.DisplayName()] = option
-		} else {
-			optionListing = append(optionListing, option)
-		}
+	if len(allArgs) > 0 && allArgs[0] == completeCommand {
+		p.complete(allArgs[1:])
+		return nil, nil, ShowCompletionErr{}
 	}
 
-	optionNames, args := extractOptions(allArgs...)
-	for _, optionName := range optionNames {
-		var option *Option
-
-		for _, f := range p.Options {
-			if f.IsPositional == true {
-				continue
-			}
-			if f.IsPublicName(optionName) == true {
-				if _, ok := requiredOptions[f.DisplayName()]; ok {
-					delete(requiredOptions, f.DisplayName())
-				} else if _, ok := remainderOptions[f.DisplayName()]; ok {
-					continue
-				}
-				option = f
-				break
-			}
-		}
-
-		if option == nil {
-			return nil, nil, InvalidOptionErr{optionName}
-		}
+	allArgs, err := expandResponseFiles(allArgs, 0)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		args, err = option.DesiredAction(p, option, args...)
-		if err != nil {
+	ns, leftovers, err := p.parse(allArgs...)
+	if err == nil {
+		if err = p.writeBindings(); err != nil {
 			return nil, nil, err
 		}
 	}
+	p.leftovers = leftovers
+	return ns, leftovers, err
+}
 
-	if len(args) > 0 {
-		for _, opt := range remainderOptions {
-			if _, ok := requiredOptions[opt.DisplayName()]; ok {
-				delete(requiredOptions, opt.DisplayName())
-			}
-			_, err := opt.DesiredAction(p, opt, args...)
-			if err != nil {
-				return nil, nil, err
-			}
-		}
+// ParseContext parses the provided arguments, as with Parse, while the provided
+// context remains active. The context is checked before parsing, and before the
+// action of each option and positional option is run, so that a canceled context
+// stops the parse with the context's error, such as context.Canceled. Actions,
+// including those of subcommands, retrieve the context using Context, such as
+// to cancel any I/O they perform, while validators added by ValidateContext
+// are provided the context.
+func (p *Parser) ParseContext(ctx context.Context, allArgs ...string) (*Namespace, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	for _, f := range p.Options {
-		if f.IsPositional == false {
-			continue
-		}
-		if _, ok := requiredOptions[f.DestName]; ok {
-			delete(requiredOptions, f.DestName)
-		}
-		args, err = f.DesiredAction(p, f, args...)
-		if err != nil {
-			return nil, nil, err
-		}
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+	return p.Parse(allArgs...)
+}
+
+// ParseArgs parses the program's arguments, excluding the program path, as
+// provided by os.Args. When the parser does not yet have a program name, it is
+// set to the name of the program specified by the program path.
+func (p *Parser) ParseArgs() (*Namespace, []string, error) {
+	if len(os.Args) < 1 {
+		return p.Parse()
 	}
 
-	if len(requiredOptions) != 0 {
-		for _, option := range requiredOptions {
-			return nil, nil, MissingOptionErr{option.DisplayName()}
-		}
+	if len(p.ProgramName) == 0 {
+		p.Path(os.Args[0])
 	}
-	return p.Namespace, args, nil
+	return p.Parse(os.Args[1:]...)
+}
+
+// MustParse parses the provided arguments, as with Parse, exiting the program
+// instead of returning an error. When the help or version text was shown, or
+// shell completions were output, the program exits with status 0. Otherwise, an
+// error is output along with the parser's usage, and the program exits with
+// status 2. The program exits using os.Exit, unless another function was set
+// by SetExitFunc; when that function returns, nil values are returned.
+func (p *Parser) MustParse(allArgs ...string) (*Namespace, []string) {
+	ns, args, err := p.Parse(allArgs...)
+	switch err.(type) {
+	case nil:
+		return ns, args
+	case ShowHelpErr, ShowVersionErr, ShowCompletionErr:
+		p.exit(0)
+	default:
+		fmt.Fprintln(p.errorOutput(), p.FormatError(err))
+		p.exit(2)
+	}
+	return nil, nil
 }
 
 // Path will set the parser's program name to the program name specified by the
@@ -305,30 +355,1732 @@ func (p *Parser) Usage(usage string) *Parser {
 	return p
 }
 
-// ShowHelp outputs to stdout the parser's generated help text.
-func (p *Parser) ShowHelp() *Parser {
-	fmt.Println(p.GetHelp())
+// SetDescription sets the description of the parser, which is listed within the
+// help text above its options, as with Usage. The description is wrapped to the
+// width of the help text, while its newlines are preserved.
+func (p *Parser) SetDescription(description string) *Parser {
+	return p.Usage(description)
+}
 
+// SetEpilog sets the text listed within the help text below the parser's
+// options, such as examples or related commands. As with the description, the
+// epilog is wrapped to the width of the help text, while its newlines are
+// preserved, so that each example can be kept on its own line. The leading
+// spaces of each line are also preserved, indenting the line once wrapped.
+func (p *Parser) SetEpilog(epilog string) *Parser {
+	p.EpilogText = epilog
 	return p
 }
 
-// ShowVersion outputs to stdout the parser's generated versioning text.
-func (p *Parser) ShowVersion() *Parser {
-	fmt.Println(p.GetVersion())
+// Group lists the provided options under a header with the specified title
+// within the help text, such as "Input options". Groups are listed in the order
+// they are added, after any options which do not belong to a group. An option
+// is only listed within the first group it is added to.
+func (p *Parser) Group(title string, options ...*Option) *Parser {
+	p.helpGroups = append(p.helpGroups, helpGroup{title: title, options: options})
+	return p
+}
 
+// MutuallyExclusive adds a group of options, identified by their public names,
+// which cannot be used together when parsing. An option can belong to multiple
+// groups. It panics if a name does not belong to any of the parser's options,
+// which must be added beforehand.
+func (p *Parser) MutuallyExclusive(names ...string) *Parser {
+	p.checkGroupNames(names)
+	p.exclusiveGroups = append(p.exclusiveGroups, names)
 	return p
 }
 
-// Version sets the provide string as the version text for the parser.
-func (p *Parser) Version(version string) *Parser {
-	p.VersionDesc = version
+// PrintError outputs the provided error, as formatted by FormatError, to the
+// parser's error output.
+func (p *Parser) PrintError(err error) *Parser {
+	fmt.Fprintln(p.errorOutput(), p.FormatError(err))
+
+	return p
+}
+
+// PrintHelp outputs the parser's generated help text to the provided writer,
+// colorized according to the parser's color mode.
+func (p *Parser) PrintHelp(w io.Writer) *Parser {
+	fmt.Fprintln(w, p.getHelp(p.colorizes(w)))
+
+	return p
+}
+
+// RequiredOneOf adds a group of options, identified by their public names, of
+// which exactly one must be used when parsing. An option can belong to multiple
+// groups. It panics if a name does not belong to any of the parser's options,
+// which must be added beforehand.
+func (p *Parser) RequiredOneOf(names ...string) *Parser {
+	p.checkGroupNames(names)
+	p.requiredGroups = append(p.requiredGroups, names)
+	return p
+}
+
+// Reset replaces the parser's namespace with a new namespace containing the
+// default value of each option, discarding any values from a previous parse.
+// The parser's options and commands remain unmodified.
+func (p *Parser) Reset() *Parser {
+	p.Namespace = NewNamespace()
+	for _, option := range p.Options {
+		p.Namespace.Set(option.DestName, option.DefaultVal)
+	}
+	p.supplied = nil
+	p.leftovers = nil
+	p.unknown = nil
+	return p
+}
+
+// Result returns the values of the parser's options from the last parse, keyed
+// by each option's first long name, or otherwise its destination name, suitable
+// for encoding as JSON. Values are converted to their option's type: flags to
+// bool, counters and int options to int, and so on, while options expecting
+// several arguments are slices, and maps remain maps. Options which were not set
+// have their default value. The arguments not bound to any option are listed
+// under the `_positionals` key, and any selected command under `command`.
+func (p *Parser) Result() map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, option := range p.Options {
+		if option == p.helpOption {
+			continue
+		}
+		key := option.DestName
+		for _, name := range option.PublicNames {
+			if len(name) > 1 {
+				key = name
+				break
+			}
+		}
+		result[key] = option.getTypedValue(p.Namespace.Get(option.DestName))
+	}
+
+	if command, ok := p.Namespace.Get("command").(string); ok == true && len(p.Commands) > 0 {
+		result["command"] = command
+	}
+	result["_positionals"] = append([]string{}, p.leftovers...)
+	return result
+}
+
+// SetAllowAbbreviation sets whether long options can be abbreviated to any
+// unambiguous prefix of their name, such as `--verb` for `--verbose`.
+func (p *Parser) SetAllowAbbreviation(allow bool) *Parser {
+	p.AllowAbbrev = allow
+	return p
+}
+
+// SetAllowPlusFlags sets whether a boolean flag's short name can be prefixed by
+// `+` to invert its meaning, such as `+x` to unset the flag set by `-x`, as with
+// the `set` builtin of shells.
+func (p *Parser) SetAllowPlusFlags(allow bool) *Parser {
+	p.PlusFlags = allow
+	return p
+}
+
+// SetCaseInsensitive sets whether long options are matched regardless of case
+// when parsing, such as `--Verbose` for `--verbose`. By default, long options
+// are case-sensitive.
+func (p *Parser) SetCaseInsensitive(insensitive bool) *Parser {
+	p.CaseInsensitive = insensitive
+	return p
+}
+
+// SetHelpSort sets the order in which options are listed within each section of
+// the help text. By default, SortDeclared lists options in the order they were
+// added, keeping related options together, while SortAlphabetical lists them by
+// their long names, case-insensitively, or by their short names when they have no
+// long name. Positional options are always listed in the order they were added,
+// as are the options within the usage.
+func (p *Parser) SetHelpSort(order HelpSort) *Parser {
+	p.HelpSort = order
+	return p
+}
+
+// SetIgnoreUnknown sets whether unrecognized options are collected, rather than
+// failing the parse, so that a program can forward or log the options it does
+// not yet understand. An unknown option without an attached value is collected
+// along with the argument following it, unless that argument is an option. The
+// collected arguments are retrieved using Unknown, while the known options are
+// parsed as usual.
+func (p *Parser) SetIgnoreUnknown(ignore bool) *Parser {
+	p.IgnoreUnknown = ignore
+	return p
+}
+
+// SetExitFunc sets the function called by MustParse to exit the program with a
+// status code, such as to record the status within tests. By default, os.Exit
+// is used.
+func (p *Parser) SetExitFunc(fn func(code int)) *Parser {
+	p.ExitFunc = fn
+	return p
+}
+
+// SetInterspersed sets whether options and positional arguments can be given
+// in any order, such as `prog file1 -v file2`, which is the default. Otherwise,
+// as required by POSIX, the first positional argument ends the options, and each
+// argument following it is a positional argument.
+func (p *Parser) SetInterspersed(interspersed bool) *Parser {
+	p.NoInterspersed = !interspersed
+	return p
+}
+
+// SetShortCaseInsensitive sets whether short options are matched regardless of
+// case when parsing, such as `-V` for `-v`. By default, short options are
+// case-sensitive, as `-v` and `-V` often represent different options.
+func (p *Parser) SetShortCaseInsensitive(insensitive bool) *Parser {
+	p.ShortCaseInsensitive = insensitive
+	return p
+}
+
+// SetSingleDashLong sets whether a long option can be prefixed by a single `-`,
+// such as `-verbose` for `--verbose`. Only an argument naming a long option in
+// full is treated as that option; otherwise, it is a cluster of short options.
+func (p *Parser) SetSingleDashLong(allow bool) *Parser {
+	p.SingleDashLong = allow
+	return p
+}
+
+// SetSlashPrefix sets whether options can also be prefixed by `/`, as is common
+// for Windows programs, such as `/v` or `/verbose`. A value can be attached using
+// `:`, such as `/out:file.txt`. Only arguments naming an option are treated as
+// options, so other arguments beginning with `/`, such as paths, are unaffected.
+func (p *Parser) SetSlashPrefix(allow bool) *Parser {
+	p.SlashPrefix = allow
+	return p
+}
+
+// SetStopEarly sets whether parsing stops at the first positional argument, the
+// first unrecognized option, or a `--` terminator. The arguments from that point
+// onwards are returned unmodified, such as for forwarding to another program.
+func (p *Parser) SetStopEarly(stop bool) *Parser {
+	p.StopEarly = stop
+	return p
+}
+
+// SetMaxHelpWidth sets the width which help text is wrapped to at most when the
+// width of the screen is detected, keeping the help text readable on very wide
+// screens. A width of zero wraps help text to the full width of the screen. By
+// default, DefaultMaxHelpWidth is used.
+func (p *Parser) SetMaxHelpWidth(width int) *Parser {
+	p.MaxHelpWidth = width
+	return p
+}
+
+// SetHelpColumn sets the column at which the descriptions of options and commands
+// begin within the help text, rather than following the longest name. Names too
+// long to fit before the column are followed by their descriptions on the next
+// line, starting at the column. A column of zero computes the column from the
+// longest name, which is the default.
+func (p *Parser) SetHelpColumn(column int) *Parser {
+	p.HelpColumn = column
+	return p
+}
+
+// SetWidth sets the width which help text is wrapped to, regardless of the width
+// of the screen or the maximum help width. A width of zero detects the width of
+// the screen instead.
+func (p *Parser) SetWidth(width int) *Parser {
+	p.Width = width
+	return p
+}
+
+// SetEnvPrefix sets the prefix of the environment variables which options take
+// their values from when they are not present while parsing. Each option with a
+// long name, such as `--max-retries`, falls back to a variable named after it,
+// such as `MYAPP_MAX_RETRIES` for the prefix "MYAPP", unless it names its own
+// variable using FromEnv, or ignores the environment using NotFromEnv. An empty
+// prefix, which is the default, derives no names.
+func (p *Parser) SetEnvPrefix(prefix string) *Parser {
+	p.EnvPrefix = prefix
+	return p
+}
+
+// SetErrorOutput sets the writer which errors are output to by PrintError. By
+// default, errors are output to stderr.
+func (p *Parser) SetErrorOutput(w io.Writer) *Parser {
+	p.ErrorOutput = w
+	return p
+}
+
+// SetOutput sets the writer which the help and versioning text are output to.
+// By default, they are output to stdout.
+func (p *Parser) SetOutput(w io.Writer) *Parser {
+	p.Output = w
+	return p
+}
+
+// SetWarningOutput sets the writer which warnings, such as for deprecated
+// options, are output to. By default, warnings are output to the parser's error
+// output.
+func (p *Parser) SetWarningOutput(w io.Writer) *Parser {
+	p.WarningOutput = w
+	return p
+}
+
+// SetSeparators sets the characters which can separate a long option from its
+// attached value, such as `:` for `--option:value`. By default, only `=` is used.
+func (p *Parser) SetSeparators(separators string) *Parser {
+	p.Separators = separators
 	return p
 }
 
+// ShowHelp outputs the parser's generated help text to the parser's output.
+func (p *Parser) ShowHelp() *Parser {
+	return p.PrintHelp(p.output())
+}
+
+// ShowVersion outputs the parser's generated versioning text to the parser's
+// output.
+func (p *Parser) ShowVersion() *Parser {
+	fmt.Fprintln(p.output(), p.GetVersion())
+
+	return p
+}
+
+// Unknown returns the unrecognized options collected during the last parse, along
+// with their values, in the order they were provided, such as
+// `[--new-flag value -x]`. Options are only collected when unknown options are
+// ignored, using SetIgnoreUnknown.
+func (p *Parser) Unknown() []string {
+	return p.unknown
+}
+
+// Version sets the provide string as the version text for the parser.
+func (p *Parser) Version(version string) *Parser {
+	p.VersionDesc = version
+	return p
+}
+
+// WasSet returns true if the option with the provided public name was present
+// within the arguments of the last parse, including a positional option which was
+// bound to any arguments. An option taking its value from its environment
+// variable, the config file, or its default value was not set.
+func (p *Parser) WasSet(name string) bool {
+	option, err := p.GetOption(name)
+	return err == nil && p.supplied[option] == true
+}
+
+// addDefaultHelp prepends a help option to the parser, using whichever of the
+// `h` and `help` names have not already been claimed by another option. No
+// option is added if help has been disabled or both names are claimed.
+func (p *Parser) addDefaultHelp() {
+	if p.HelpDisabled == true {
+		return
+	}
+
+	var names []string
+	for _, name := range []string{"h", "help"} {
+		claimed := false
+		for _, option := range p.Options {
+			if option.IsPositional == false && option.IsPublicName(name) == true {
+				claimed = true
+				break
+			}
+		}
+		if claimed == false {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	helpOption := NewOption(strings.Join(names, " "), "help", "Show program help").Action(ShowHelp)
+	p.Options = append([]*Option{helpOption}, p.Options...)
+	p.helpOption = helpOption
+	p.defaultHelp = true
+}
+
+// checkGroups returns an error if the supplied options include more than one
+// option from a mutually exclusive group, or not exactly one option from a group
+// requiring one of its options. Negated flags are not included among the
+// supplied options.
+func (p *Parser) checkGroups(supplied map[*Option]bool) error {
+	groupNames := func(names []string) ([]string, []string, error) {
+		var all []string
+		var used []string
+		for _, name := range names {
+			name = strings.TrimLeft(name, "-")
+			option, err := p.GetOption(name)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			all = append(all, prefixedName(name))
+			if supplied[option] == true {
+				used = append(used, prefixedName(name))
+			}
+		}
+		return all, used, nil
+	}
+
+	for _, group := range p.exclusiveGroups {
+		_, used, err := groupNames(group)
+		if err != nil {
+			return err
+		} else if len(used) > 1 {
+			return MutuallyExclusiveErr{used}
+		}
+	}
+
+	for _, group := range p.requiredGroups {
+		all, used, err := groupNames(group)
+		if err != nil {
+			return err
+		} else if len(used) > 1 {
+			return MutuallyExclusiveErr{used}
+		} else if len(used) == 0 {
+			return RequiredOneOfErr{all}
+		}
+	}
+
+	return nil
+}
+
+// checkGroupNames panics if any of the provided public names, identifying the
+// options of a group, does not belong to one of the parser's options.
+func (p *Parser) checkGroupNames(names []string) {
+	for _, name := range names {
+		name = strings.TrimLeft(name, "-")
+		if _, err := p.GetOption(name); err != nil {
+			panic(fmt.Sprintf("option name '%s' of group does not match any option", prefixedName(name)))
+		}
+	}
+}
+
+// checkOptionNames panics if any public name of the provided non-positional
+// option is already used by another non-positional option. An automatically
+// added help option instead yields the name, and is added again when needed.
+func (p *Parser) checkOptionNames(f *Option) {
+	if f.IsPositional == true {
+		return
+	}
+
+	for _, name := range f.PublicNames {
+		for _, option := range p.Options {
+			if option.IsPositional == true || option.IsPublicName(name) == false {
+				continue
+			}
+
+			if option == p.helpOption && p.defaultHelp == true {
+				for i := range p.Options {
+					if p.Options[i] == option {
+						p.Options = append(p.Options[:i], p.Options[i+1:]...)
+						break
+					}
+				}
+				p.helpOption = nil
+				p.defaultHelp = false
+				break
+			}
+
+			msg := "option name '%s' of \"%s\" is already used by \"%s\""
+			panic(fmt.Sprintf(msg, prefixedName(name), f.HelpText, option.HelpText))
+		}
+	}
+}
+
+// commandIndex returns the index of the first argument which is not an option
+// or the value of an option, and therefore names a subcommand. If there is no
+// such argument, -1 is returned.
+func (p *Parser) commandIndex(allArgs ...string) int {
+	valued := p.valuedNames()
+
+	for i := 0; i < len(allArgs); i++ {
+		if allArgs[i] == "--" {
+			return -1
+		}
+
+		options, _ := extractValuedOptions(valued, p.separators(), allArgs[i])
+		if len(options) == 0 {
+			return i
+		}
+
+		last := options[len(options)-1]
+		if valued[last.name] == false {
+			continue
+		}
+		if option, err := p.matchOption(last.name); err == nil {
+			if num, err := strconv.Atoi(option.ArgNum); err == nil {
+				i = i + num
+			} else {
+				i++
+			}
+
+			// An attached value is the first of the option's values.
+			if last.hasValue == true {
+				i--
+			}
+		}
+	}
+
+	return -1
+}
+
+// dashLongOptions returns the provided arguments with any argument consisting of
+// a single `-` followed by a long option's complete name, such as `-verbose`,
+// prefixed by `--` instead, when single-dash long options are enabled. Arguments
+// following a `--` terminator remain unmodified.
+func (p *Parser) dashLongOptions(allArgs []string) []string {
+	if p.SingleDashLong == false {
+		return allArgs
+	}
+
+	longNames := make(map[string]bool)
+	for _, option := range p.Options {
+		for _, name := range option.PublicNames {
+			if len(name) > 1 && option.IsPositional == false {
+				longNames[p.foldName(name)] = true
+			}
+		}
+	}
+
+	dashed := make([]string, len(allArgs))
+	for i, a := range allArgs {
+		if a == "--" {
+			copy(dashed[i:], allArgs[i:])
+			break
+		}
+
+		dashed[i] = a
+		if len(a) < 3 || a[0] != '-' || a[1] == '-' {
+			continue
+		}
+
+		end := len(a)
+		if index := strings.IndexAny(a, p.separators()); index >= 0 {
+			end = index
+		}
+		if longNames[p.foldName(a[1:end])] == true {
+			dashed[i] = "-" + a
+		}
+	}
+	return dashed
+}
+
+// contextErr returns the error of the context provided to ParseContext, once the
+// context is canceled or its deadline passes, or otherwise nil.
+func (p *Parser) contextErr() error {
+	if p.ctx == nil {
+		return nil
+	}
+	return p.ctx.Err()
+}
+
+// errorOutput returns the writer which errors are output to, defaulting to
+// stderr.
+func (p *Parser) errorOutput() io.Writer {
+	if p.ErrorOutput == nil {
+		return os.Stderr
+	}
+	return p.ErrorOutput
+}
+
+// exit exits the program with the provided status code, using the parser's exit
+// function, defaulting to os.Exit.
+func (p *Parser) exit(code int) {
+	if p.ExitFunc == nil {
+		os.Exit(code)
+	}
+	p.ExitFunc(code)
+}
+
+// foldLongOptions returns the provided arguments with the names of any long
+// options lowercased, when long options are case-insensitive. Their attached
+// values, and any arguments following a `--` terminator, remain unmodified.
+func (p *Parser) foldLongOptions(allArgs []string) []string {
+	if p.CaseInsensitive == false {
+		return allArgs
+	}
+
+	folded := make([]string, len(allArgs))
+	for i, a := range allArgs {
+		if a == "--" {
+			copy(folded[i:], allArgs[i:])
+			break
+		} else if strings.HasPrefix(a, "--") == false {
+			folded[i] = a
+			continue
+		}
+
+		end := len(a)
+		if index := strings.IndexAny(a, p.separators()); index >= 0 {
+			end = index
+		}
+		folded[i] = strings.ToLower(a[:end]) + a[end:]
+	}
+	return folded
+}
+
+// foldName returns the provided option name lowercased when options of its
+// length are case-insensitive, or otherwise unmodified.
+func (p *Parser) foldName(name string) string {
+	if (len(name) > 1 && p.CaseInsensitive == true) || (len(name) == 1 && p.ShortCaseInsensitive == true) {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// getCommand returns the subcommand parser with the provided name, prepared to
+// share the current parser's namespace, or otherwise returns an error.
+func (p *Parser) getCommand(name string) (*Parser, error) {
+	var names []string
+	for _, command := range p.Commands {
+		if command.CommandName == name {
+			command.Prog(join(" ", p.ProgramName, name))
+			command.Namespace = p.Namespace
+			return command, nil
+		}
+		names = append(names, command.CommandName)
+	}
+
+	return nil, InvalidCommandErr{name, names}
+}
+
+// getEnvValue returns the value of the provided option's environment variable,
+// whose name may be derived from the parser's environment prefix, and true if
+// that variable is set to a non-empty value. Options showing help or the version
+// are only taken from an environment variable set by FromEnv.
+func (p *Parser) getEnvValue(option *Option) (string, bool) {
+	action := reflect.ValueOf(option.DesiredAction).Pointer()
+	if option == p.helpOption || action == reflect.ValueOf(ShowHelp).Pointer() || action == reflect.ValueOf(ShowVersion).Pointer() {
+		if len(option.EnvVar) == 0 {
+			return "", false
+		}
+	}
+	return option.getEnvValue(p.EnvPrefix)
+}
+
+// applyEnvValue gives the value of the provided option's environment variable to
+// the option's action, as when the option is present on the command line. A flag
+// is set by a boolean value, such as `false`, while other options expecting no
+// arguments take the number of times they are present, such as `2` for a
+// counter, or a boolean value for once or never. Options expecting several
+// arguments take the whitespace-separated fields of the value.
+func (p *Parser) applyEnvValue(option *Option, value string) error {
+	if stored, ok := option.getFlagValue(); ok == true {
+		flag, err := parseBool(value)
+		if err != nil {
+			return InvalidValueErr{*option, value, err}
+		}
+		p.Namespace.Set(option.DestName, strconv.FormatBool(flag == stored))
+		return nil
+	} else if option.ArgNum == "0" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			flag, err := parseBool(value)
+			if err != nil {
+				return InvalidValueErr{*option, value, err}
+			}
+			count = 0
+			if flag == true {
+				count = 1
+			}
+		}
+		for i := 0; i < count; i++ {
+			if _, err := option.DesiredAction(p, option); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	args := []string{value}
+	if num, err := strconv.Atoi(option.ArgNum); (err == nil && num > 1) || strings.ContainsAny(option.ArgNum, "*+rR") {
+		args = strings.Fields(value)
+	}
+	_, err := option.DesiredAction(p, option, args...)
+	return err
+}
+
+// getHelp returns the parser's help text, as described by GetHelp, colorized when
+// specified.
+func (p *Parser) getHelp(color bool) string {
+	p.addDefaultHelp()
+
+	// The width is determined once per render, and passed down to each
+	// section, as detecting the width of the screen may initialize termbox.
+	screenWidth := p.helpWidth()
+
+	var positional []*Option
+	var notPositional []*Option
+	var usage []string
+
+	header := []string{"usage:", p.ProgramName}
+	headerIndent := len(join(" ", header...))
+	headerLen := headerIndent
+
+	var notPosArgs []string
+	var posArgs []string
+	longest := 0
+
+	for _, arg := range p.Options {
+		if arg.IsHidden == true {
+			continue
+		}
+		if arg.IsPositional == false {
+			notPositional = append(notPositional, arg)
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+
+	grouped := make([][]*Option, len(p.helpGroups))
+	var ungroupedPositional []*Option
+	var ungrouped []*Option
+	for _, arg := range append(positional, notPositional...) {
+		if i := p.helpGroupIndex(arg); i >= 0 {
+			grouped[i] = append(grouped[i], arg)
+		} else if arg.IsPositional == true {
+			ungroupedPositional = append(ungroupedPositional, arg)
+		} else {
+			ungrouped = append(ungrouped, arg)
+		}
+	}
+	if p.HelpSort == SortAlphabetical {
+		sortOptions(ungrouped)
+		for _, group := range grouped {
+			sortOptions(group)
+		}
+	}
+
+	for _, arg := range notPositional {
+		displayName := arg.getHelpName()
+		if len(displayName) > longest {
+			longest = len(displayName)
+		}
+
+		argUsg := arg.GetUsage()
+		notPosArgs = append(notPosArgs, arg.GetUsage())
+		headerLen = headerLen + len(argUsg)
+		if headerLen+len(argUsg) > screenWidth {
+			headerLen = headerIndent
+			notPosArgs = append(notPosArgs, join("", "\n", spacer(headerIndent)))
+		}
+	}
+
+	for _, arg := range positional {
+		displayName := arg.GetUsage()
+		if len(displayName) > longest {
+			longest = len(displayName)
+		}
+
+		argUsg := arg.GetUsage()
+		posArgs = append(posArgs, arg.GetUsage())
+		headerLen = headerLen + len(argUsg)
+		if headerLen+len(argUsg) > screenWidth {
+			headerLen = headerIndent
+			posArgs = append(posArgs, join("", "\n", spacer(headerIndent)))
+		}
+	}
+
+	var commandNames []string
+	for _, command := range p.Commands {
+		commandNames = append(commandNames, command.CommandName)
+		if len(command.CommandName) > longest {
+			longest = len(command.CommandName)
+		}
+	}
+
+	longest = longest + 4
+	if p.HelpColumn > 0 {
+		longest = p.HelpColumn
+	}
+
+	header = append(header, notPosArgs...)
+	header = append(header, posArgs...)
+	if len(commandNames) > 0 {
+		header = append(header, join("", "{", join(",", commandNames...), "}"), "...")
+	}
+
+	usage = append(usage, join(" ", header...), "\n")
+
+	if len(p.UsageText) > 0 {
+		usage = append(usage, "\n", join("\n", wordWrap(p.UsageText, screenWidth)...), "\n")
+	}
+
+	if len(ungroupedPositional) > 0 {
+		usage = append(usage, "\n", p.styleHeader("positional arguments:", color), "\n")
+		usage = append(usage, helpColumns(ungroupedPositional, longest, screenWidth, color)...)
+	}
+
+	if len(p.Commands) > 0 {
+		usage = append(usage, "\n", p.styleHeader("commands:", color), "\n")
+
+		var lines []string
+		for _, command := range p.Commands {
+			name := command.CommandName
+			lines = append(lines, "  ", styleName(name, color))
+			lines = append(lines, helpColumnPadding(name, longest, screenWidth)...)
+
+			for _, helpLine := range wordWrapIndent(command.UsageText, screenWidth, longest) {
+				lines = append(lines, helpLine, "\n")
+			}
+		}
+		usage = append(usage, lines...)
+	}
+
+	if len(ungrouped) > 0 {
+		// Once options are grouped, the remaining options have a header
+		// consistent with the titles of those groups.
+		title := "optional arguments:"
+		if len(p.helpGroups) > 0 {
+			title = "Options:"
+		}
+		usage = append(usage, "\n", p.styleHeader(title, color), "\n")
+		usage = append(usage, helpColumns(ungrouped, longest, screenWidth, color)...)
+	}
+
+	for i, group := range p.helpGroups {
+		if len(grouped[i]) > 0 {
+			usage = append(usage, "\n", p.styleHeader(group.title+":", color), "\n")
+			usage = append(usage, helpColumns(grouped[i], longest, screenWidth, color)...)
+		}
+	}
+
+	if len(p.EpilogText) > 0 {
+		// The leading spaces of each line are kept as its indent, such as
+		// for the commands of examples.
+		var lines []string
+		for _, line := range strings.Split(p.EpilogText, "\n") {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			lines = append(lines, WrapText(line, screenWidth, indent, true))
+		}
+		usage = append(usage, "\n", join("\n", lines...), "\n")
+	}
+
+	return join("", usage...)
+}
+
+// helpWidth returns the width which the help text is wrapped to: the parser's
+// width when set, or otherwise the width of the screen, capped at the parser's
+// maximum help width. DefaultScreenWidth is used if the width of the screen
+// cannot be determined.
+func (p *Parser) helpWidth() int {
+	if p.Width > 0 {
+		return p.Width
+	}
+
+	width, err := screenWidthFunc()
+	if err != nil {
+		width = DefaultScreenWidth
+	}
+	if p.MaxHelpWidth > 0 && width > p.MaxHelpWidth {
+		width = p.MaxHelpWidth
+	}
+	return width
+}
+
+// helpGroupIndex returns the index of the first help group containing the
+// provided option, or -1 if the option does not belong to a group.
+func (p *Parser) helpGroupIndex(option *Option) int {
+	for i, group := range p.helpGroups {
+		for _, member := range group.options {
+			if member == option {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// helpColumns returns the lines listing the provided options within the help
+// text, with each option's name in the first column, and its help text wrapped
+// within the second column, which begins at the specified indent. Names are
+// emboldened when the help text is colorized.
+func helpColumns(options []*Option, indent, screenWidth int, color bool) []string {
+	var lines []string
+	for _, arg := range options {
+		name := arg.getHelpName()
+
+		lines = append(lines, "  ", styleName(name, color))
+		lines = append(lines, helpColumnPadding(name, indent, screenWidth)...)
+
+		for _, helpLine := range wordWrapIndent(arg.GetHelpText(), screenWidth, indent) {
+			lines = append(lines, helpLine, "\n")
+		}
+	}
+	return lines
+}
+
+// helpColumnPadding returns the padding following a name within the first column
+// of the help text, which begins the second column at the specified indent. When
+// the name leaves less than two spaces before the indent, or the indent exceeds
+// the width of the screen, the second column begins on the next line instead.
+func helpColumnPadding(name string, indent, screenWidth int) []string {
+	if len(name)+4 > indent {
+		return []string{"\n", spacer(indent)}
+	}
+
+	padding := []string{spacer(indent - len(name) - 2)}
+	if indent > screenWidth {
+		padding = append(padding, "\n", spacer(indent))
+	}
+	return padding
+}
+
+// isOption returns true if the provided argument names any of the parser's
+// options, including negated flags, or otherwise false.
+func (p *Parser) isOption(a string) bool {
+	for _, extractedOption := range splitOption(a, p.valuedNames(), p.separators()) {
+		if _, err := p.matchOption(extractedOption.name); err == nil || p.matchNegation(extractedOption.name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// locateErr returns the provided error identifying the argument it occurred
+// within, by its index within the arguments being parsed and its text, when the
+// error supports it. Otherwise, the error is returned unmodified.
+func (p *Parser) locateErr(err error, index int, token string) error {
+	switch e := err.(type) {
+	case AmbiguousOptionErr:
+		e.Index, e.Token = p.offset+index, token
+		return e
+	case InvalidOptionErr:
+		e.Index, e.Token = p.offset+index, token
+		return e
+	}
+	return err
+}
+
+// matchNegation retrieves the negatable option whose long name matches the
+// provided name without its `no-` prefix, or nil if there is no such option.
+func (p *Parser) matchNegation(name string) *Option {
+	name = p.foldName(name)
+	if strings.HasPrefix(name, "no-") == false || len(name) <= 4 {
+		return nil
+	}
+
+	for _, option := range p.Options {
+		if option.IsPositional == true || option.IsNegatable == false {
+			continue
+		}
+		for _, publicName := range option.PublicNames {
+			if len(publicName) > 1 && p.foldName(publicName) == name[3:] {
+				return option
+			}
+		}
+	}
+	return nil
+}
+
+// matchOption retrieves the non-positional option matching the provided name.
+// When abbreviations are allowed, a long name which is not an exact match will
+// match the single option whose long name begins with it. An error is returned
+// if no option, or more than one option, matches the name.
+func (p *Parser) matchOption(name string) (*Option, error) {
+	var matches []*Option
+	var candidates []string
+
+	for _, option := range p.Options {
+		if option.IsPositional == true {
+			continue
+		}
+		for _, publicName := range option.PublicNames {
+			if p.foldName(publicName) == p.foldName(name) {
+				return option, nil
+			}
+		}
+		if p.AllowAbbrev == false || len(name) <= 1 {
+			continue
+		}
+		for _, publicName := range option.PublicNames {
+			if len(publicName) > 1 && strings.HasPrefix(p.foldName(publicName), p.foldName(name)) {
+				matches = append(matches, option)
+				candidates = append(candidates, "--"+publicName)
+				break
+			}
+		}
+	}
+
+	if len(matches) > 1 {
+		return nil, AmbiguousOptionErr{name: name, candidates: candidates}
+	} else if len(matches) == 0 {
+		return nil, InvalidOptionErr{name: name, suggestion: p.suggestOption(name)}
+	}
+	return matches[0], nil
+}
+
+// output returns the writer which the help and versioning text are output to,
+// defaulting to stdout.
+func (p *Parser) output() io.Writer {
+	if p.Output == nil {
+		return os.Stdout
+	}
+	return p.Output
+}
+
+// parse parses the provided arguments once any response files have been
+// expanded, as described by Parse.
+func (p *Parser) parse(allArgs ...string) (*Namespace, []string, error) {
+	if p.Namespace == nil {
+		p.Namespace = NewNamespace()
+	}
+	p.addDefaultHelp()
+	original := allArgs
+	// Plus flags are rewritten last, so that their short names are not folded
+	// as long names would be.
+	allArgs = p.plusOptions(p.foldLongOptions(p.dashLongOptions(p.slashOptions(allArgs))))
+
+	if len(p.Commands) > 0 {
+		if index := p.commandIndex(allArgs...); index >= 0 {
+			return p.parseCommand(allArgs[:index], allArgs[index], allArgs[index+1:])
+		}
+	}
+
+	// When stopping early, only the arguments preceeding the first positional
+	// or unrecognized argument are parsed; the rest are returned verbatim.
+	var passthrough []string
+	if p.StopEarly == true {
+		index := p.stopIndex(true, allArgs...)
+		if index < len(allArgs) && allArgs[index] == "--" {
+			passthrough = append([]string{}, original[index+1:]...)
+		} else {
+			passthrough = append([]string{}, original[index:]...)
+		}
+		allArgs = allArgs[:index]
+	}
+
+	// The arguments captured by a remainder option are never interpreted.
+	remainder, index := p.remainderIndex(allArgs)
+	var raw []string
+	if remainder != nil {
+		raw = append([]string{}, original[index:len(allArgs)]...)
+		allArgs = allArgs[:index]
+	}
+	allArgs = p.terminatePositionals(allArgs)
+
+	requiredOptions := make(map[string]*Option)
+	remainderOptions := make(map[string]*Option)
+	var errs ParseErrors
+	var err error
+
+	var optionListing []*Option
+	envValues := make(map[*Option]string)
+
+	for _, option := range p.Options {
+		// An option's environment variable takes precedence over the config file,
+		// which takes precedence over its default value. A value from either
+		// satisfies the option when it is required. Values from the environment
+		// are given to the option's action once the command line is parsed.
+		var value interface{} = option.DefaultVal
+		isSet := false
+		if envValue, fromEnv := p.getEnvValue(option); fromEnv == true && option.IsPositional == false {
+			envValues[option], isSet = envValue, true
+		} else if fromEnv == true {
+			if err := validateArg(p.Context(), *option, envValue); err != nil {
+				errs = append(errs, err)
+			}
+			value, isSet = envValue, true
+		} else if key, configValue, ok := p.getConfigValue(option); ok == true {
+			if err := validateConfigValue(p.Context(), *option, key, configValue); err != nil {
+				errs = append(errs, err)
+			}
+			value, isSet = configValue, true
+		}
+
+		if option.IsRequired == true && isSet == false {
+			requiredOptions[option.DisplayName()] = option
+		}
+		p.Namespace.Set(option.DestName, value)
+		if strings.ToLower(option.ArgNum) == "r" {
+			remainderOptions[option.DisplayName()] = option
+		} else {
+			optionListing = append(optionListing, option)
+		}
+	}
+
+	extracted, args, values := p.extractArgs(allArgs)
+
+	// Showing help takes precedence over any other options or errors.
+	if p.helpOption != nil {
+		for _, extractedOption := range extracted {
+			if option, _ := p.matchOption(extractedOption.name); option == p.helpOption {
+				_, err := p.helpOption.DesiredAction(p, p.helpOption)
+				return nil, nil, err
+			}
+		}
+	}
+
+	supplied := make(map[*Option]bool)
+	p.supplied = supplied
+	occurrences := make(map[*Option]int)
+	negations := make(map[*Option]bool)
+
+	for i, extractedOption := range extracted {
+		if err := p.contextErr(); err != nil {
+			return nil, nil, err
+		}
+
+		option, err := p.matchOption(extractedOption.name)
+		if err != nil {
+			negated := p.matchNegation(extractedOption.name)
+			if _, ok := err.(InvalidOptionErr); ok == true && negated == nil {
+				// A cluster of unknown short options, such as a mistyped
+				// `-xyz`, is reported once, as a whole.
+				if first, whole := p.unknownCluster(extracted, i); whole == true && first == false {
+					continue
+				} else if p.IgnoreUnknown == true {
+					p.unknown = append(p.unknown, unknownArgs(extracted, i, original, whole)...)
+					continue
+				} else if whole == true {
+					token := original[extractedOption.index]
+					err = InvalidOptionErr{name: token, suggestion: p.suggestOption(strings.TrimLeft(token, "-"))}
+				}
+			}
+
+			if negated == nil {
+				errs = append(errs, p.locateErr(err, extractedOption.index, original[extractedOption.index]))
+				continue
+			} else if extractedOption.hasValue == true {
+				errs = append(errs, UnexpectedValueErr{*negated, extractedOption.value})
+				continue
+			}
+			delete(requiredOptions, negated.DisplayName())
+			p.Namespace.Set(negated.DestName, "false")
+			p.warnDeprecated(negated, extractedOption.name, supplied)
+			supplied[negated] = true
+			occurrences[negated]++
+			negations[negated] = true
+			continue
+		}
+		p.warnDeprecated(option, extractedOption.name, supplied)
+		supplied[option] = true
+		occurrences[option]++
+		delete(negations, option)
+
+		if _, ok := requiredOptions[option.DisplayName()]; ok {
+			delete(requiredOptions, option.DisplayName())
+		} else if _, ok := remainderOptions[option.DisplayName()]; ok {
+			errs = append(errs, p.locateErr(InvalidOptionErr{name: extractedOption.name}, extractedOption.index, original[extractedOption.index]))
+			continue
+		}
+
+		// An attached value is provided to the option's action ahead of
+		// any other arguments. An option requiring a value without one
+		// must not claim an unrelated argument instead.
+		if extractedOption.hasValue == true {
+			if stored, ok := option.getFlagValue(); ok == true {
+				// A boolean flag can be explicitly assigned, such as `--flag=false`.
+				value, err := parseBool(extractedOption.value)
+				if err != nil {
+					errs = append(errs, InvalidValueErr{*option, extractedOption.value, err})
+					continue
+				} else if value == false {
+					p.Namespace.Set(option.DestName, strconv.FormatBool(!stored))
+					continue
+				}
+			} else if option.ArgNum == "0" {
+				errs = append(errs, UnexpectedValueErr{*option, extractedOption.value})
+				continue
+			} else if num, err := strconv.Atoi(option.ArgNum); err == nil && num > 1 {
+				// An option expecting several values takes them from the
+				// arguments following it, rather than from any positionals.
+				received := append([]string{extractedOption.value}, values[extractedOption.index]...)
+				if len(received) < num {
+					errs = append(errs, TooFewValuesErr{*option, num, len(received)})
+					continue
+				}
+				args = append(received, args...)
+			} else {
+				args = append([]string{extractedOption.value}, args...)
+			}
+		} else if option.IsValueOptional == true {
+			// Without an attached value, the option's implicit value is used.
+			if _, err := option.DesiredAction(p, option, option.ConstVal); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		} else if option.ArgNum == "+" || argNumRegex.MatchString(option.ArgNum) {
+			// An option followed by another option was most likely given
+			// without its value, which must then be attached instead.
+			if next := extractedOption.index + 1; next < len(allArgs) && p.isOption(allArgs[next]) {
+				attached := join("", "-", extractedOption.name, original[next])
+				if len(extractedOption.name) > 1 {
+					attached = join("", "--", extractedOption.name, p.separators()[:1], original[next])
+				}
+				errs = append(errs, OptionAsValueErr{*option, original[next], attached})
+			} else {
+				errs = append(errs, TooFewArgsErr{*option})
+			}
+			continue
+		}
+
+		args, err = option.DesiredAction(p, option, args...)
+		switch err.(type) {
+		case nil:
+		case ShowHelpErr, ShowVersionErr:
+			return nil, nil, err
+		case StopErr:
+			// Stopping skips the checks following the options, such as for
+			// required options, but not the errors of preceding options.
+			if len(errs) == 1 {
+				return nil, nil, errs[0]
+			} else if len(errs) > 1 {
+				return nil, nil, errs
+			}
+			return p.Namespace, append(args, passthrough...), nil
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	for _, option := range p.Options {
+		if envValue, ok := envValues[option]; ok == true && supplied[option] == false {
+			if err := p.applyEnvValue(option, envValue); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, option := range p.Options {
+		if option.IsPositional == true {
+			continue
+		}
+		count := occurrences[option]
+		if count < option.MinCount || (option.MaxCount > 0 && count > option.MaxCount) {
+			errs = append(errs, OccurrencesErr{*option, count})
+		}
+	}
+
+	// A flag whose last occurrence negated it does not count towards its
+	// groups, such as `--no-quiet` alongside `--verbose`.
+	grouped := make(map[*Option]bool)
+	for option := range supplied {
+		if negations[option] == false {
+			grouped[option] = true
+		}
+	}
+	if err := p.checkGroups(grouped); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(args) > 0 {
+		for _, opt := range remainderOptions {
+			if _, ok := requiredOptions[opt.DisplayName()]; ok {
+				delete(requiredOptions, opt.DisplayName())
+			}
+			if _, err := opt.DesiredAction(p, opt, args...); err != nil {
+				errs = append(errs, err)
+			}
+			supplied[opt] = true
+		}
+	}
+
+	for _, f := range p.Options {
+		if f.IsPositional == false {
+			continue
+		}
+		if _, ok := requiredOptions[f.DisplayName()]; ok {
+			delete(requiredOptions, f.DisplayName())
+		}
+		if f == remainder {
+			args = append(args, raw...)
+		}
+		if err := p.contextErr(); err != nil {
+			return nil, nil, err
+		}
+		count := len(args)
+		args, err = f.DesiredAction(p, f, args...)
+		if err != nil {
+			errs = append(errs, err)
+		} else if len(args) < count {
+			supplied[f] = true
+		}
+	}
+
+	// Once positional options are declared, any arguments remaining after
+	// they are bound are unexpected, unless an option consumes the remainder.
+	if len(args) > 0 && len(remainderOptions) == 0 {
+		hasPositional := false
+		hasCatchAll := false
+		for _, f := range p.Options {
+			if f.IsPositional == true {
+				hasPositional = true
+				if strings.ContainsAny(f.ArgNum, "*+rR") {
+					hasCatchAll = true
+				}
+			}
+		}
+		if hasPositional == true && hasCatchAll == false {
+			errs = append(errs, TooManyArgsErr{args})
+		}
+	}
+
+	if len(requiredOptions) != 0 {
+		var missing []string
+		for _, option := range p.Options {
+			if _, ok := requiredOptions[option.DisplayName()]; ok {
+				missing = append(missing, option.DisplayName())
+			}
+		}
+		errs = append(errs, MissingOptionErr{missing})
+	}
+	args = append(args, passthrough...)
+
+	// A single error is returned as-is, while several errors are returned
+	// together so that each of them can be reported.
+	if len(errs) == 1 {
+		return nil, nil, errs[0]
+	} else if len(errs) > 1 {
+		return nil, nil, errs
+	}
+	return p.Namespace, args, nil
+}
+
+// parseCommand parses the arguments preceeding a subcommand using the current
+// parser, and then dispatches the remaining arguments to the subcommand's parser.
+// The name of the subcommand is stored under the `command` key. The `help`
+// subcommand, unless defined by the user, will show the help text for the
+// subcommand named after it.
+func (p *Parser) parseCommand(head []string, name string, tail []string) (*Namespace, []string, error) {
+	if name == "help" && p.HelpDisabled == false {
+		if _, err := p.getCommand(name); err != nil {
+			if len(tail) == 0 {
+				p.ShowHelp()
+				return nil, nil, ShowHelpErr{}
+			}
+
+			command, err := p.getCommand(tail[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			command.ShowHelp()
+			return nil, nil, ShowHelpErr{}
+		}
+	}
+
+	command, err := p.getCommand(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, _, err := p.parse(head...); err != nil {
+		return nil, nil, err
+	}
+
+	p.Namespace.Set("command", name)
+	command.offset = p.offset + len(head) + 1
+	command.unknown = nil
+	command.ctx = p.ctx
+	ns, args, err := command.parse(tail...)
+	command.ctx = nil
+	p.unknown = append(p.unknown, command.unknown...)
+	return ns, args, err
+}
+
+// plusOptions returns the provided arguments with any argument consisting of a
+// `+` followed by the short name of a boolean flag, such as `+x`, replaced by an
+// explicit assignment inverting the flag, such as `--x=false`, when plus flags
+// are allowed. Arguments following a `--` terminator remain unmodified.
+func (p *Parser) plusOptions(allArgs []string) []string {
+	if p.PlusFlags == false {
+		return allArgs
+	}
+
+	plussed := make([]string, len(allArgs))
+	for i, a := range allArgs {
+		if a == "--" {
+			copy(plussed[i:], allArgs[i:])
+			break
+		}
+
+		plussed[i] = a
+		if len(a) != 2 || a[0] != '+' {
+			continue
+		}
+
+		if option, err := p.matchOption(a[1:]); err == nil && option.IsPositional == false {
+			if _, ok := option.getFlagValue(); ok == true {
+				plussed[i] = join("", "--", a[1:], p.separators()[:1], "false")
+			}
+		}
+	}
+	return plussed
+}
+
+// separators returns the characters separating a long option from its attached
+// value, defaulting to `=` when none have been set. When options can be prefixed
+// by `/`, `:` is always a separator.
+func (p *Parser) separators() string {
+	separators := p.Separators
+	if separators == "" {
+		separators = "="
+	}
+	if p.SlashPrefix == true && strings.Contains(separators, ":") == false {
+		separators = separators + ":"
+	}
+	return separators
+}
+
+// slashOptions returns the provided arguments with any argument consisting of a
+// `/` followed by the name of an option, such as `/v` or `/out:file.txt`, prefixed
+// by `--` instead, when options can be prefixed by `/`. Other arguments, such as
+// paths, and arguments following a `--` terminator remain unmodified.
+func (p *Parser) slashOptions(allArgs []string) []string {
+	if p.SlashPrefix == false {
+		return allArgs
+	}
+
+	slashed := make([]string, len(allArgs))
+	for i, a := range allArgs {
+		if a == "--" {
+			copy(slashed[i:], allArgs[i:])
+			break
+		}
+
+		slashed[i] = a
+		if len(a) < 2 || a[0] != '/' {
+			continue
+		}
+
+		end := len(a)
+		if index := strings.IndexAny(a, p.separators()); index >= 0 {
+			end = index
+		}
+		if option, err := p.matchOption(a[1:end]); err == nil && option.IsPositional == false {
+			slashed[i] = "--" + a[1:]
+		}
+	}
+	return slashed
+}
+
+// remainderIndex returns the parser's first remainder option, along with the
+// index of the first argument it captures, which follows the arguments bound to
+// the positional options preceding it: their numbers of arguments, with `?` and
+// `+` binding one, and `*` binding none. Options and their values are skipped,
+// while the arguments following a `--` terminator are all positional. Without a
+// remainder option, nil is returned along with the number of arguments.
+func (p *Parser) remainderIndex(allArgs []string) (*Option, int) {
+	var remainder *Option
+	count := 0
+	for _, option := range p.Options {
+		if option.IsPositional == false {
+			continue
+		} else if option.IsRemainder == true {
+			remainder = option
+			break
+		}
+
+		switch option.ArgNum {
+		case "?", "+":
+			count++
+		default:
+			num, _ := strconv.Atoi(option.ArgNum)
+			count += num
+		}
+	}
+	if remainder == nil {
+		return nil, len(allArgs)
+	}
+
+	index := 0
+	for ; index < len(allArgs); index++ {
+		index += p.stopIndex(false, allArgs[index:]...)
+		if index >= len(allArgs) {
+			break
+		} else if allArgs[index] == "--" {
+			index += 1 + count
+			break
+		} else if count == 0 {
+			break
+		}
+
+		// Once the preceding positional options are bound, the remainder
+		// begins immediately, whether or not it resembles an option.
+		count--
+		if count == 0 {
+			index++
+			break
+		}
+	}
+
+	if index > len(allArgs) {
+		index = len(allArgs)
+	}
+	return remainder, index
+}
+
+// sortOptions sorts the provided options alphabetically by their first long
+// names, case-insensitively, or by their first short names when they do not
+// have long names. Positional options precede the others in their existing
+// order, as do options with equal names.
+func sortOptions(options []*Option) {
+	key := func(option *Option) string {
+		name := ""
+		for _, publicName := range option.PublicNames {
+			if len(publicName) > 1 {
+				return strings.ToLower(publicName)
+			} else if len(name) == 0 {
+				name = publicName
+			}
+		}
+		return strings.ToLower(name)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].IsPositional == true || options[j].IsPositional == true {
+			return options[i].IsPositional == true && options[j].IsPositional == false
+		}
+		return key(options[i]) < key(options[j])
+	})
+}
+
+// stopIndex returns the index of the first argument which is a positional
+// argument, an unrecognized option when specified, or a `--` terminator, or
+// otherwise the number of arguments. The values of recognized options are
+// skipped.
+func (p *Parser) stopIndex(unrecognized bool, allArgs ...string) int {
+	valued := p.valuedNames()
+
+	for i := 0; i < len(allArgs); i++ {
+		if allArgs[i] == "--" {
+			return i
+		}
+
+		options, _ := extractValuedOptions(valued, p.separators(), allArgs[i])
+		if len(options) == 0 {
+			return i
+		}
+
+		var option *Option
+		for _, extractedOption := range options {
+			var err error
+			if option, err = p.matchOption(extractedOption.name); err != nil {
+				if option = p.matchNegation(extractedOption.name); option == nil && unrecognized == true {
+					return i
+				}
+			}
+		}
+
+		last := options[len(options)-1]
+		if valued[last.name] == false {
+			continue
+		}
+
+		count := 1
+		if num, err := strconv.Atoi(option.ArgNum); err == nil {
+			count = num
+		}
+		if last.hasValue == true {
+			count--
+		}
+		for ; count > 0 && i+1 < len(allArgs); count-- {
+			if next, _ := extractValuedOptions(valued, p.separators(), allArgs[i+1]); len(next) > 0 || allArgs[i+1] == "--" {
+				break
+			}
+			i++
+		}
+	}
+
+	return len(allArgs)
+}
+
+// suggestOption returns the long option name closest to the provided unknown
+// name, as long as it is within two edits, or otherwise an empty string.
+func (p *Parser) suggestOption(name string) string {
+	suggestion := ""
+	closest := 3
+
+	for _, option := range p.Options {
+		if option.IsPositional == true || option.IsHidden == true {
+			continue
+		}
+		for _, publicName := range option.PublicNames {
+			if len(publicName) <= 1 {
+				continue
+			}
+			if distance := levenshtein(name, publicName); distance < closest {
+				suggestion = publicName
+				closest = distance
+			}
+		}
+	}
+	return suggestion
+}
+
+// warnDeprecated outputs a warning when the provided option, or the alias it was
+// supplied with, is deprecated and the option has not already been supplied,
+// using the name the option was supplied with.
+func (p *Parser) warnDeprecated(option *Option, name string, supplied map[*Option]bool) {
+	if supplied[option] == true {
+		return
+	}
+
+	w := p.WarningOutput
+	if w == nil {
+		w = p.errorOutput()
+	}
+
+	for alias, message := range option.AliasWarnings {
+		if p.foldName(alias) != p.foldName(name) {
+			continue
+		} else if len(message) > 0 {
+			fmt.Fprintf(w, "warning: %s is deprecated: %s\n", prefixedName(name), message)
+			return
+		}
+		for _, publicName := range option.PublicNames {
+			if len(publicName) > 1 && option.isAlias(publicName) == false {
+				fmt.Fprintf(w, "warning: %s is deprecated, use %s\n", prefixedName(name), prefixedName(strings.ToLower(publicName)))
+				return
+			}
+		}
+		fmt.Fprintf(w, "warning: %s is deprecated\n", prefixedName(name))
+		return
+	}
+
+	if option.IsDeprecated == false {
+		return
+	} else if len(option.DeprecatedText) > 0 {
+		fmt.Fprintf(w, "warning: %s is deprecated: %s\n", prefixedName(name), option.DeprecatedText)
+	} else {
+		fmt.Fprintf(w, "warning: %s is deprecated\n", prefixedName(name))
+	}
+}
+
+// terminatePositionals returns the provided arguments with a `--` terminator
+// inserted before the first positional argument, unless a terminator precedes it,
+// when the arguments are not interspersed. Otherwise, the arguments are returned
+// unmodified.
+func (p *Parser) terminatePositionals(allArgs []string) []string {
+	if p.NoInterspersed == false {
+		return allArgs
+	}
+
+	index := p.stopIndex(false, allArgs...)
+	if index >= len(allArgs) || allArgs[index] == "--" {
+		return allArgs
+	}
+	terminated := append(append([]string{}, allArgs[:index]...), "--")
+	return append(terminated, allArgs[index:]...)
+}
+
+// valuedNames returns the set of public names belonging to the parser's
+// non-positional options which expect one or more arguments.
+func (p *Parser) valuedNames() map[string]bool {
+	valued := make(map[string]bool)
+	for _, option := range p.Options {
+		if option.IsPositional == true || option.IsValueOptional == true || option.ArgNum == "0" || strings.ContainsAny(option.ArgNum, "rR") {
+			continue
+		}
+		for _, name := range option.PublicNames {
+			valued[p.foldName(name)] = true
+			if len(name) == 1 && p.ShortCaseInsensitive == true {
+				valued[strings.ToUpper(name)] = true
+			}
+
+			// Unambiguous abbreviations of a long name also expect a value.
+			for i := 2; p.AllowAbbrev == true && i < len(name); i++ {
+				if match, err := p.matchOption(name[:i]); err == nil && match == option {
+					valued[p.foldName(name[:i])] = true
+				}
+			}
+		}
+	}
+	return valued
+}
+
+// valueCounts returns the number of values expected by each of the provided
+// names of options expecting a value, for those expecting more than one.
+func (p *Parser) valueCounts(valued map[string]bool) map[string]int {
+	counts := make(map[string]int)
+	for name := range valued {
+		if option, err := p.matchOption(name); err == nil {
+			if num, err := strconv.Atoi(option.ArgNum); err == nil && num > 1 {
+				counts[name] = num
+			}
+		}
+	}
+	return counts
+}
+
+// extractArgs extracts the options from the provided arguments, along with their
+// values, as with extractCountedOptions. When unknown options are ignored, an
+// unknown option ending its argument without an attached value takes the
+// argument following it as its value, unless that argument is an option.
+func (p *Parser) extractArgs(allArgs []string) ([]extractedOption, []string, map[int][]string) {
+	valued := p.valuedNames()
+	counts := p.valueCounts(valued)
+	extracted, args, values := extractCountedOptions(valued, counts, p.separators(), allArgs...)
+	if p.IgnoreUnknown == false {
+		return extracted, args, values
+	}
+
+	unknown := false
+	for i, extractedOption := range extracted {
+		if extractedOption.hasValue == true || (i+1 < len(extracted) && extracted[i+1].index == extractedOption.index) {
+			continue
+		}
+		if _, err := p.matchOption(extractedOption.name); err == nil || p.matchNegation(extractedOption.name) != nil {
+			continue
+		} else if _, ok := err.(InvalidOptionErr); ok == true {
+			valued[extractedOption.name] = true
+			unknown = true
+		}
+	}
+
+	if unknown == false {
+		return extracted, args, values
+	}
+	return extractCountedOptions(valued, counts, p.separators(), allArgs...)
+}
+
+// unknownArgs returns the arguments representing the extracted option at the
+// provided index, using the original arguments: the option's own argument, or
+// its name and any attached value within a cluster of short options, followed by
+// its detached value. When whole, the option's entire cluster is represented by
+// its argument, followed by the detached value of the cluster's last option.
+func unknownArgs(extracted []extractedOption, i int, original []string, whole bool) []string {
+	option := extracted[i]
+	clustered := (i > 0 && extracted[i-1].index == option.index) || (i+1 < len(extracted) && extracted[i+1].index == option.index)
+	if whole == true {
+		for i+1 < len(extracted) && extracted[i+1].index == option.index {
+			i++
+		}
+		option, clustered = extracted[i], false
+	}
+
+	var unknown []string
+	if clustered == false {
+		unknown = append(unknown, original[option.index])
+	} else if option.hasValue == true && option.detached == false {
+		unknown = append(unknown, prefixedName(option.name)+option.value)
+	} else {
+		unknown = append(unknown, prefixedName(option.name))
+	}
+
+	if option.detached == true {
+		unknown = append(unknown, original[option.index+1])
+	}
+	return unknown
+}
+
+// unknownCluster returns whether the extracted option at the provided index is
+// the first of the options extracted from its argument, and whether that
+// argument is a cluster of short options which are all unknown, such as `-xyz`.
+func (p *Parser) unknownCluster(extracted []extractedOption, i int) (bool, bool) {
+	first := i == 0 || extracted[i-1].index != extracted[i].index
+
+	count := 0
+	for _, extractedOption := range extracted {
+		if extractedOption.index != extracted[i].index {
+			continue
+		} else if _, err := p.matchOption(extractedOption.name); err == nil || p.matchNegation(extractedOption.name) != nil {
+			return first, false
+		} else if _, ok := err.(InvalidOptionErr); ok == false {
+			return first, false
+		}
+		count++
+	}
+	return first, count > 1
+}
+
 // NewParser returns an instantiated pointer to a new parser instance, with
 // a description matching the provided string.
 func NewParser(desc string) *Parser {
-	p := Parser{UsageText: desc}
+	p := Parser{UsageText: desc, AllowAbbrev: true, MaxHelpWidth: DefaultMaxHelpWidth}
 	p.Namespace = NewNamespace()
 
 	if len(os.Args) >= 1 {
@@ -2,8 +2,14 @@ package argparse
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 ) //import go package for testing related functionality
 
 // TestParserAddHelp tests the AddHelp method to ensure two help options
@@ -43,6 +49,119 @@ func TestParserAddOption(t *testing.T) {
 	}
 }
 
+// TestParserParse_Help tests the Parse method to ensure a help option is added
+// automatically using any unclaimed names, takes precedence over missing required
+// options, and can be disabled.
+func TestParserParse_Help(t *testing.T) {
+	oldStdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = oldStdout
+		devNull.Close()
+	}()
+
+	p := NewParser("parser")
+	p.AddOption(NewOption("o output", "output", "output file").Nargs("1").Action(Store).Required())
+
+	if _, _, err := p.Parse("--help"); err != (ShowHelpErr{}) {
+		t.Errorf("Expected a ShowHelpErr, but received: '%v'", err)
+	}
+
+	if _, _, err := p.Parse("-h"); err != (ShowHelpErr{}) {
+		t.Errorf("Expected a ShowHelpErr, but received: '%v'", err)
+	}
+
+	p = NewParser("parser")
+	p.AddOption(NewOption("h host", "host", "host name").Nargs("1").Action(Store))
+
+	ns, _, err := p.Parse("-h", "localhost")
+	if err != nil || ns.String("host") != "localhost" {
+		t.Errorf("Expected host 'localhost', but received: '%s' (%v)", ns.String("host"), err)
+	}
+
+	if _, _, err := p.Parse("--help"); err != (ShowHelpErr{}) {
+		t.Errorf("Expected a ShowHelpErr, but received: '%v'", err)
+	}
+
+	p = NewParser("parser").DisableHelpFlag()
+	if _, _, err := p.Parse("--help"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+}
+
+// TestParserParse_Commands tests the Parse method to ensure that arguments are
+// dispatched to the subcommand named by the first positional argument, and that
+// unknown subcommands result in an error listing the valid subcommands.
+func TestParserParse_Commands(t *testing.T) {
+	p := NewParser("parser").Prog("tool")
+	p.AddOption(NewOption("o output", "output", "output file").Nargs("1").Action(Store))
+
+	add := p.AddCommand("add", "Add a file")
+	add.AddOption(NewFlag("f force", "force", "force adding"))
+	p.AddCommand("list", "List files")
+
+	ns, args, err := p.Parse("-o", "out.txt", "add", "--force", "file.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if ns.String("command") != "add" {
+		t.Errorf("Expected command 'add', but received: '%s'", ns.String("command"))
+	}
+
+	if ns.String("output") != "out.txt" || ns.String("force") != "true" {
+		t.Errorf("Unexpected namespace values: %v", ns.Mapping)
+	}
+
+	if len(args) != 1 || args[0] != "file.txt" {
+		t.Errorf("Expected remaining arguments: '[file.txt]' but received: '%v'", args)
+	}
+
+	_, _, err = p.Parse("remove")
+	expected := `invalid command "remove" (choose from: add, list)`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error: '%s' but received: '%v'", expected, err)
+	}
+}
+
+// TestParserParse_CommandHelp tests that the help text of a parser lists its
+// subcommands, and that `help <command>` shows the subcommand's help.
+func TestParserParse_CommandHelp(t *testing.T) {
+	oldStdout := os.Stdout
+	readFile, writeFile, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	os.Stdout = writeFile
+
+	p := NewParser("parser").Prog("tool")
+	add := p.AddCommand("add", "Add a file")
+	add.AddOption(NewFlag("f force", "force", "force adding"))
+
+	_, _, err = p.Parse("help", "add")
+	writeFile.Close()
+	os.Stdout = oldStdout
+
+	if err != (ShowHelpErr{}) {
+		t.Errorf("Expected a ShowHelpErr, but received: '%v'", err)
+	}
+
+	var buf bytes.Buffer
+	buf.ReadFrom(readFile)
+	if strings.Contains(buf.String(), "usage: tool add") == false {
+		t.Errorf("Expected subcommand help, but received: '%s'", buf.String())
+	}
+
+	help := p.GetHelp()
+	if strings.Contains(help, "commands:") == false || strings.Contains(help, "  add") == false {
+		t.Errorf("Expected help to list subcommands, but received: '%s'", help)
+	}
+}
+
 // TestParserGetOption_InvalidOption tests retreival of an error and nil for a option
 // from a Parser instance by specifying an incorrect PublicName attribute.
 func TestParserGetOption_InvalidOption(t *testing.T) {
@@ -106,6 +225,277 @@ func TestParserGetHelp(t *testing.T) {
 	// TODO: implement a better test for the Parser.GetHelp() method.
 }
 
+// TestParserGetHelp_Width tests that the help text is wrapped to the width set
+// using SetWidth, regardless of the width of the screen.
+func TestParserGetHelp_Width(t *testing.T) {
+	p := NewParser("a program which does a great many things with a great many files").Prog("tool").SetWidth(40)
+	p.AddOptions(
+		NewOption("o output", "output", "the file which all of the output of the program is written to").Nargs("1").Action(Store),
+		NewFlag("v verbose", "verbose", "output additional details about each of the files being processed"),
+	)
+
+	for _, line := range strings.Split(p.GetHelp(), "\n") {
+		if len(line) > 40 {
+			t.Errorf("Expected help lines of at most 40 characters, but received: '%s'", line)
+		}
+	}
+}
+
+// TestParserGetHelp_MaxHelpWidth tests that the help text is wrapped to the
+// maximum help width on a wide screen, unless the maximum is disabled.
+func TestParserGetHelp_MaxHelpWidth(t *testing.T) {
+	oldColumns, hadColumns := os.LookupEnv("COLUMNS")
+	defer func() {
+		if hadColumns {
+			os.Setenv("COLUMNS", oldColumns)
+		} else {
+			os.Unsetenv("COLUMNS")
+		}
+	}()
+	os.Setenv("COLUMNS", "250")
+
+	description := strings.Repeat("a very long program description ", 10)
+	p := NewParser(description).Prog("prog")
+	p.AddOption(NewOption("o output", "output", strings.Repeat("the file to write the output to ", 10)).Nargs("1").Action(Store))
+
+	for _, line := range strings.Split(p.GetHelp(), "\n") {
+		if len(line) > DefaultMaxHelpWidth {
+			t.Errorf("Expected help lines of at most %d characters, but received: '%s'", DefaultMaxHelpWidth, line)
+		}
+	}
+
+	longest := 0
+	for _, line := range strings.Split(p.SetMaxHelpWidth(0).GetHelp(), "\n") {
+		if len(line) > longest {
+			longest = len(line)
+		}
+	}
+	if longest <= DefaultMaxHelpWidth || longest > 250 {
+		t.Errorf("Expected help lines wrapped to the screen width, but the longest was %d characters", longest)
+	}
+}
+
+// TestParserPrintHelp_ScreenWidth tests that the width of the screen is detected
+// once per rendering of the help text, however many sections it contains, and
+// not at all when the parser has a fixed width.
+func TestParserPrintHelp_ScreenWidth(t *testing.T) {
+	calls := 0
+	defer func(fn func() (int, error)) { screenWidthFunc = fn }(screenWidthFunc)
+	screenWidthFunc = func() (int, error) {
+		calls++
+		return 80, nil
+	}
+
+	output := NewOption("o output", "output", "the output file").Nargs("1").Action(Store)
+	p := NewParser("parser").Prog("tool")
+	p.AddOptions(
+		NewOption("i input", "input", "the input file").Nargs("1").Action(Store),
+		output,
+		NewArg("src", "src", "source file"),
+	)
+	p.Group("Output", output)
+	p.AddCommand("build", "build the project")
+
+	var b bytes.Buffer
+	p.PrintHelp(&b)
+	if calls != 1 {
+		t.Errorf("Expected the screen width to be detected once, but it was detected %d times", calls)
+	}
+
+	calls = 0
+	p.SetWidth(60).PrintHelp(&b)
+	if calls != 0 {
+		t.Errorf("Expected the screen width not to be detected, but it was detected %d times", calls)
+	}
+}
+
+// TestParserGetHelp_HelpColumn tests that a fixed help column aligns the help
+// text of each option at that column, while an option whose name is too long to
+// fit before it has its help text on the next line, starting at the column.
+func TestParserGetHelp_HelpColumn(t *testing.T) {
+	p := NewParser("parser").Prog("tool").SetWidth(80).SetHelpColumn(24)
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewFlag("q quiet", "quiet", "quiet output"),
+		NewOption("include-experimental-features", "experimental", "enable experimental features").Nargs("1").Action(Store),
+	)
+
+	help := p.GetHelp()
+	for _, expected := range []string{
+		"\n  -h, --help            Show program help\n",
+		"\n  -v, --[no-]verbose    verbose output\n",
+		"\n  -q, --[no-]quiet      quiet output\n",
+		"\n  --include-experimental-features INCLUDE_EXPERIMENTAL_FEATURES\n" + spacer(24) + "enable experimental features\n",
+	} {
+		if strings.Contains(help, expected) == false {
+			t.Errorf("Expected help text to contain %q, but received:\n%s", expected, help)
+		}
+	}
+
+	p.SetHelpColumn(0)
+	if expected := "  -v, --[no-]verbose" + spacer(45) + "verbose output\n"; strings.Contains(p.GetHelp(), expected) == false {
+		t.Errorf("Expected help text to contain %q, but received:\n%s", expected, p.GetHelp())
+	}
+}
+
+// TestParserGetHelp_Whitespace tests that tabs and runs of spaces within help
+// text, such as from indented source strings, are collapsed to single spaces
+// when the help text is wrapped, while its newlines are preserved.
+func TestParserGetHelp_Whitespace(t *testing.T) {
+	p := NewParser("parser").Prog("tool").SetWidth(40)
+	p.AddOption(NewFlag("v verbose", "verbose", `output	details   of each
+		step,  including	the files which were changed`))
+
+	help := p.GetHelp()
+	expected := "  -v, --[no-]verbose  output details of\n" +
+		"                      each\n" +
+		"                      step, including\n" +
+		"                      the files which\n" +
+		"                      were changed\n"
+	if strings.Contains(help, expected) == false {
+		t.Errorf("Expected help text to contain %q, but received:\n%s", expected, help)
+	}
+}
+
+// TestParserGetHelp_Epilog tests that the description is listed above the options
+// and the epilog below them, each wrapped to the width of the help text with
+// their newlines preserved, along with the indent of each line of the epilog.
+func TestParserGetHelp_Epilog(t *testing.T) {
+	p := NewParser("").Prog("tool").SetWidth(40)
+	p.SetDescription("Synchronize the files within two directories, copying only the files which changed.")
+	p.SetEpilog("examples:\n  tool -v src dst\n  tool --dry-run --exclude '*.tmp' src/ backup/\n\nSee also: rsync(1)")
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+
+	expected := "usage: tool [-h] [-v]\n\n" +
+		"Synchronize the files within two\n" +
+		"directories, copying only the files\n" +
+		"which changed.\n\n" +
+		"optional arguments:\n" +
+		"  -h, --help          Show program help\n" +
+		"  -v, --[no-]verbose  verbose output\n\n" +
+		"examples:\n" +
+		"  tool -v src dst\n" +
+		"  tool --dry-run --exclude '*.tmp' src/\n" +
+		"  backup/\n\n" +
+		"See also: rsync(1)\n"
+	if help := p.GetHelp(); help != expected {
+		t.Errorf("Expected help text:\n%s\nbut received:\n%s", expected, help)
+	}
+}
+
+// TestParserGetHelp_Sort tests that options are listed in the order they were
+// added by default, and by long name, or else by short name, when sorted
+// alphabetically, within both ungrouped and grouped options.
+func TestParserGetHelp_Sort(t *testing.T) {
+	format := NewOption("format", "format", "the output format").Nargs("1").Action(Store)
+	output := NewOption("output", "output", "the output file").Nargs("1").Action(Store)
+
+	p := NewParser("parser").Prog("tool").SetWidth(80)
+	p.AddOptions(
+		NewOption("zeta", "zeta", "the zeta value").Nargs("1").Action(Store),
+		NewOption("b", "b", "the b value").Nargs("1").Action(Store),
+		NewOption("alpha", "alpha", "the alpha value").Nargs("1").Action(Store),
+		output,
+		format,
+	)
+	p.Group("Output", output, format)
+
+	order := func(names ...string) bool {
+		help := p.GetHelp()
+		help = help[strings.Index(help, "Options:"):]
+		last := -1
+		for _, name := range names {
+			index := strings.Index(help, "\n  "+name+" ")
+			if index <= last {
+				return false
+			}
+			last = index
+		}
+		return true
+	}
+
+	if order("-h, --help", "--zeta", "-b", "--alpha", "--output", "--format") == false {
+		t.Errorf("Expected options in the order they were added, but received:\n%s", p.GetHelp())
+	}
+
+	p.SetHelpSort(SortAlphabetical)
+	if order("--alpha", "-b", "-h, --help", "--zeta", "--format", "--output") == false {
+		t.Errorf("Expected options in alphabetical order, but received:\n%s", p.GetHelp())
+	}
+}
+
+// TestParserGetHelp_Groups tests that grouped options are listed under the title
+// of their group, in the order the groups were added, after any ungrouped options.
+func TestParserGetHelp_Groups(t *testing.T) {
+	input := NewOption("i input", "input", "the input file").Nargs("1").Action(Store)
+	format := NewOption("f format", "format", "the output format").Nargs("1").Action(Store)
+	output := NewOption("o output", "output", "the output file").Nargs("1").Action(Store)
+
+	p := NewParser("parser").Prog("tool").SetWidth(80)
+	p.AddOptions(input, format, output, NewFlag("verbose", "verbose", "verbose output"))
+	p.Group("Input options", input).Group("Output options", output, format)
+
+	expected := join("\n",
+		"Options:",
+		"  -h, --help           Show program help",
+		"  --[no-]verbose       verbose output",
+		"",
+		"Input options:",
+		"  -i, --input INPUT    the input file",
+		"",
+		"Output options:",
+		"  -f, --format FORMAT  the output format",
+		"  -o, --output OUTPUT  the output file",
+		"",
+	)
+
+	help := p.GetHelp()
+	if strings.Contains(help, expected) == false {
+		t.Errorf("Expected the help text to contain:\n%s\nbut received:\n%s", expected, help)
+	}
+}
+
+// TestParserGetUsage tests that the one-line usage lists optional options within
+// brackets by their short names, followed by required options without brackets,
+// and positional options according to their number of arguments.
+func TestParserGetUsage(t *testing.T) {
+	p := NewParser("parser").Prog("prog")
+	p.AddOptions(
+		NewFlag("verbose v", "verbose", "verbose output"),
+		NewOption("out o", "output", "output file").Nargs("1").Action(Store),
+		NewOption("level", "level", "the level").Nargs("1").Action(Store).Required(),
+		NewArg("src", "src", "source file"),
+		NewArg("dst", "dst", "destination file").Nargs("?"),
+		NewArg("files", "files", "other files").Nargs("*"),
+	)
+
+	expected := "usage: prog [-h] [-v] [-o OUT] --level LEVEL <src> [dst] [files...]"
+	if usage := p.GetUsage(); usage != expected {
+		t.Errorf("Expected usage: '%s' but received: '%s'", expected, usage)
+	}
+}
+
+// TestParserGetHelp_MetaVar tests that an option's meta variable names its
+// arguments within both the usage and the option's line of the help text.
+func TestParserGetHelp_MetaVar(t *testing.T) {
+	p := NewParser("parser").Prog("tool").SetWidth(80)
+	p.AddOptions(
+		NewOption("o output", "output", "the output file").Nargs("1").Action(Store).MetaVar("file"),
+		NewOption("i include", "include", "the include directories").Nargs("+").Action(Append).MetaVar("dir"),
+	)
+
+	if usage := p.GetUsage(); strings.Contains(usage, "[-o FILE] [-i DIR [DIR ...]]") == false {
+		t.Errorf("Expected the usage to contain the meta variables, but received: '%s'", usage)
+	}
+
+	help := p.GetHelp()
+	for _, expected := range []string{"  -o, --output FILE            the output file", "  -i, --include DIR [DIR ...]  the include directories"} {
+		if strings.Contains(help, expected) == false {
+			t.Errorf("Expected the help text to contain '%s', but received:\n%s", expected, help)
+		}
+	}
+}
+
 // TestParserGetVersion tests the GetVersion  method to ensure that the parser will
 // return a version-string containing version information of the parser.
 func TestParserGetVersion(t *testing.T) {
@@ -120,67 +510,2028 @@ func TestParserGetVersion(t *testing.T) {
 // the parser are properly parsed and the necessary actions for all options are
 // executed.
 func TestParserParse(t *testing.T) {
-	// TODO: create an actual test.
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewFlag("q quiet", "quiet", "quiet output"),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+	)
+
+	ns, args, err := p.Parse("-v", "--output", "file.txt", "extra")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if ns.String("verbose") != "true" {
+		t.Errorf("Expected verbose 'true', but received: '%s'", ns.String("verbose"))
+	}
+
+	if ns.String("quiet") != "false" {
+		t.Errorf("Expected quiet 'false', but received: '%s'", ns.String("quiet"))
+	}
+
+	if ns.String("output") != "file.txt" {
+		t.Errorf("Expected output 'file.txt', but received: '%s'", ns.String("output"))
+	}
+
+	if len(args) != 1 || args[0] != "extra" {
+		t.Errorf("Expected remaining arguments: '[extra]' but received: '%v'", args)
+	}
+
+	_, _, err = p.Parse("--unknown")
+	if _, ok := err.(InvalidOptionErr); ok == false {
+		t.Errorf("Expected an InvalidOptionErr, but received: '%v'", err)
+	}
 }
 
-// TestParserPath tests the Path method to ensure that providing a filepath will
-// result in updating the parser's program name.
-func TestParserPath(t *testing.T) {
-	path := "/usr/local/bin/my_prog"
-	expected := "my_prog"
+// TestParserParse_Required tests the Parse method to ensure that every missing
+// required option is reported at once, and that defaults are used for absent options.
+func TestParserParse_Required(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("i input", "input", "input file").Nargs("1").Action(Store).Required(),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).Required(),
+		NewOption("l level", "level", "level").Nargs("1").Action(Store).Default("info"),
+		NewArg("src", "src", "source").Required(),
+	)
 
-	p := Parser{}
-	if len(p.ProgramName) != 0 {
-		t.Errorf("Parser program name should be an empty string, but is: %s", p.ProgramName)
+	_, _, err := p.Parse("source")
+	if err == nil {
+		t.Fatal("An error was expected but did not occur")
 	}
 
-	p.Path(path)
-	if p.ProgramName != expected {
-		t.Errorf(
-			"The parser's ProgramName '%s' does not match the expected name: '%s'",
-			p.ProgramName,
-			expected,
-		)
+	expected := `options "-i, --input", "-o, --output" required`
+	if err.Error() != expected {
+		t.Errorf("Expected error: '%s' but received: '%s'", expected, err.Error())
+	}
+
+	ns, _, err := p.Parse("-i", "in.txt", "-o", "out.txt", "source")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if ns.String("level") != "info" {
+		t.Errorf("Expected level 'info', but received: '%s'", ns.String("level"))
 	}
 }
 
-// TestParserProg tests the Prog method to ensure that providing a program name
-// will result in updating the parser's program name string.
-func TestParserProg(t *testing.T) {
-	name := "awesome_go_prog"
+// TestParserParse_AttachedValue tests the Parse method to ensure that a value
+// attached to a long option is stored for that option, and that attaching a value
+// to a non-boolean option which expects no arguments results in an error.
+func TestParserParse_AttachedValue(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		NewCounter("v verbose", "verbose", "verbose output"),
+	)
 
-	p := Parser{}
-	if len(p.ProgramName) != 0 {
-		t.Errorf("Parser program name should be an empty string, but is: %s", p.ProgramName)
+	ns, args, err := p.Parse("first", "--output=file.txt", "second")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
 	}
 
-	p.Prog(name)
-	if p.ProgramName != name {
-		t.Errorf(
-			"The parser's ProgramName '%s' does not match the expected name: '%s'",
-			p.ProgramName,
-			name,
-		)
+	if ns.String("output") != "file.txt" {
+		t.Errorf("Expected output 'file.txt', but received: '%s'", ns.String("output"))
+	}
+
+	if len(args) != 2 {
+		t.Errorf("Expected 2 remaining arguments, but received: %v", args)
+	}
+
+	if _, _, err = p.Parse("--verbose=yes"); err == nil {
+		t.Error("An error was expected but did not occur")
 	}
 }
 
-// TestParserUsage tests the Usage method to ensure that providing a usage string
-// will result in updating the parser's usage string.
-func TestParserUsage(t *testing.T) {
-	usage := "do stuff to accomplish things"
+// TestParserParse_BooleanValue tests the Parse method to ensure that boolean
+// flags can be explicitly assigned a true or false value, and that any other
+// value results in an error.
+func TestParserParse_BooleanValue(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("cache", "cache", "use the cache").Nargs("0").Action(StoreFalse).Default("true"),
+	)
 
-	p := Parser{}
-	if len(p.UsageText) != 0 {
-		t.Errorf("Parser usage text should be an empty string, but is: %s", p.UsageText)
+	tests := map[string]string{
+		"true": "true", "1": "true", "t": "true", "TRUE": "true", "yes": "true", "Yes": "true",
+		"false": "false", "0": "false", "f": "false", "FALSE": "false", "no": "false", "NO": "false",
+	}
+	for value, expected := range tests {
+		ns, _, err := p.Parse("--verbose=" + value)
+		if err != nil {
+			t.Errorf("An unexpected error occurred for '%s': %s", value, err.Error())
+		} else if ns.String("verbose") != expected {
+			t.Errorf("Expected verbose '%s' for '%s', but received: '%s'", expected, value, ns.String("verbose"))
+		}
 	}
 
-	p.Usage(usage)
-	if p.UsageText != usage {
-		t.Errorf(
-			"The parser's UsageText '%s' does not match the expected usage: '%s'",
-			p.UsageText,
-			usage,
-		)
+	if ns, _, _ := p.Parse("--verbose"); ns.String("verbose") != "true" {
+		t.Errorf("Expected verbose 'true', but received: '%s'", ns.String("verbose"))
+	}
+
+	if ns, _, _ := p.Parse("--cache=false"); ns.String("cache") != "true" {
+		t.Errorf("Expected cache 'true', but received: '%s'", ns.String("cache"))
+	}
+
+	_, _, err := p.Parse("--verbose=maybe")
+	expected := `-v, --verbose: invalid value "maybe": expected a boolean value such as true or false`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
+// TestParserParse_AttachedShortValue tests the Parse method to ensure that a value
+// attached to a short option expecting arguments is stored for that option.
+func TestParserParse_AttachedShortValue(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	ns, _, err := p.Parse("-vofile.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if ns.String("output") != "file.txt" {
+		t.Errorf("Expected output 'file.txt', but received: '%s'", ns.String("output"))
+	}
+
+	if ns.String("verbose") != "true" {
+		t.Errorf("Expected verbose 'true', but received: '%s'", ns.String("verbose"))
+	}
+}
+
+// TestParserParse_ShortEquals tests the Parse method to ensure that a value
+// separated from a short option by `=` is stored for that option, including an
+// empty value, and that a short flag can be assigned a boolean value that way.
+// Within a cluster, the `=` belongs to the value of the first option expecting
+// one.
+func TestParserParse_ShortEquals(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).Default("default.txt"),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	tests := []struct {
+		args    []string
+		output  string
+		verbose string
+	}{
+		{[]string{"-o=file.txt"}, "file.txt", "false"},
+		{[]string{"-o="}, "", "false"},
+		{[]string{"-oo=file.txt"}, "o=file.txt", "false"},
+		{[]string{"-v=false", "-o=a=b"}, "a=b", "false"},
+		{[]string{"-v=true", "-vo=x"}, "=x", "true"},
+	}
+	for _, test := range tests {
+		ns, _, err := p.Parse(test.args...)
+		if err != nil {
+			t.Errorf("An unexpected error occurred for %q: %s", test.args, err.Error())
+			continue
+		}
+		if ns.String("output") != test.output || ns.String("verbose") != test.verbose {
+			t.Errorf("Expected output '%s' and verbose '%s' for %q, but received: %v", test.output, test.verbose, test.args, ns.Mapping)
+		}
+	}
+}
+
+// TestParserParse_Bundled tests the Parse method to ensure that bundled short
+// options behave like getopt, with a value-taking option ending the bundle and
+// taking the following argument when the bundle has no remaining characters.
+func TestParserParse_Bundled(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("x extract", "extract", "extract files"),
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("f file", "file", "archive file").Nargs("1").Action(Store),
+	)
+
+	for _, allArgs := range [][]string{
+		{"-xvf", "archive.tar"},
+		{"-xvfarchive.tar"},
+		{"other", "-xvf", "archive.tar"},
+	} {
+		ns, _, err := p.Parse(allArgs...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred: %s", err.Error())
+		}
+
+		if ns.String("file") != "archive.tar" || ns.String("extract") != "true" || ns.String("verbose") != "true" {
+			t.Errorf("Unexpected namespace values for %v: %v", allArgs, ns.Mapping)
+		}
+	}
+
+	// As within getopt, the characters following a value-taking option are
+	// its value, even when they name other options. Rejecting `-fxv` would
+	// also reject attached values, such as `-farchive.tar`.
+	ns, _, err := p.Parse("-fxv")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("file") != "xv" || ns.String("extract") != "false" || ns.String("verbose") != "false" {
+		t.Errorf("Unexpected namespace values for [-fxv]: %v", ns.Mapping)
+	}
+
+	if _, _, err := p.Parse("other", "-xvf"); err == nil || err.Error() != "-f, --file: too few arguments" {
+		t.Errorf("Expected error '-f, --file: too few arguments', but received: '%v'", err)
+	}
+}
+
+// TestParserParse_MultipleValues tests the Parse method to ensure that an option
+// expecting several values binds exactly that many of the arguments following it,
+// and that too few of them, including when another option appears among them,
+// results in an error rather than taking positional arguments instead.
+func TestParserParse_MultipleValues(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("c coords", "coords", "coordinates").Nargs("3").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewArg("files", "files", "input files").Nargs("*"),
+	)
+
+	for _, allArgs := range [][]string{
+		{"--coords", "1", "2", "3", "a.txt"},
+		{"a.txt", "--coords", "1", "2", "3"},
+		{"--coords=1", "2", "3", "a.txt"},
+		{"-vc", "1", "2", "3", "a.txt"},
+	} {
+		ns, _, err := p.Parse(allArgs...)
+		if err != nil {
+			t.Errorf("An unexpected error occurred for %q: %s", allArgs, err.Error())
+			continue
+		}
+		if coords := ns.Slice("coords"); reflect.DeepEqual(coords, []string{"1", "2", "3"}) == false {
+			t.Errorf("Expected coords '[1 2 3]' for %q, but received: '%v'", allArgs, coords)
+		}
+		if files := ns.Slice("files"); reflect.DeepEqual(files, []string{"a.txt"}) == false {
+			t.Errorf("Expected files '[a.txt]' for %q, but received: '%v'", allArgs, files)
+		}
+	}
+
+	tests := map[string][]string{
+		"-c, --coords: expected 3 values, got 2": {"--coords", "1", "2"},
+		"-c, --coords: expected 3 values, got 1": {"--coords", "1", "-v", "2", "3"},
+	}
+	for expected, allArgs := range tests {
+		if _, _, err := p.Parse(allArgs...); err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s' for %q, but received: '%v'", expected, allArgs, err)
+		}
+	}
+
+	// Counts containing a zero, such as 10, bind each of their values.
+	ten := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	p = NewParser("parser")
+	p.AddOptions(
+		NewOption("p points", "points", "points").Nargs(10).Action(Store),
+		NewOption("a append", "appended", "appended points").Nargs(10).Action(Append),
+	)
+	ns, args, err := p.Parse(append(append([]string{"-p"}, ten...), append([]string{"-a"}, ten...)...)...)
+	if err != nil || len(args) != 0 {
+		t.Fatalf("Expected no leftovers or error, but received: %q (%v)", args, err)
+	}
+	for _, key := range []string{"points", "appended"} {
+		if values := ns.Slice(key); reflect.DeepEqual(values, ten) == false {
+			t.Errorf("Expected %s '%v', but received: '%v'", key, ten, values)
+		}
+	}
+}
+
+// TestParserParse_Positionals tests the Parse method to ensure positional options
+// are bound in declaration order, and that too few or too many positional
+// arguments result in an error.
+func TestParserParse_Positionals(t *testing.T) {
+	p := NewParser("parser").Prog("prog")
+	p.AddOptions(
+		NewArg("src", "src", "source file"),
+		NewArg("dst", "dst", "destination file"),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	ns, _, err := p.Parse("a.txt", "-v", "b.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("src") != "a.txt" || ns.String("dst") != "b.txt" {
+		t.Errorf("Unexpected namespace values: %v", ns.Mapping)
+	}
+
+	if _, _, err := p.Parse("a.txt"); err == nil {
+		t.Error("An error was expected but did not occur")
+	}
+
+	_, _, err = p.Parse("a.txt", "b.txt", "c.txt")
+	if _, ok := err.(TooManyArgsErr); ok == false {
+		t.Errorf("Expected a TooManyArgsErr, but received: '%v'", err)
+	}
+
+	p.AddOption(NewArg("files", "files", "other files").Nargs("*"))
+	ns, _, err = p.Parse("a.txt", "b.txt", "c.txt", "d.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if files := ns.Slice("files"); len(files) != 2 {
+		t.Errorf("Expected files '[c.txt d.txt]', but received: %v", files)
+	}
+
+	if strings.Contains(p.GetHelp(), "usage: prog [-h] [-v] <src> <dst> [files...]") == false {
+		t.Errorf("Unexpected usage text: '%s'", p.GetHelp())
+	}
+}
+
+// TestParserParse_Remainder tests the Parse method to ensure that a remainder
+// option captures every argument following those bound to the preceding
+// positional options verbatim, including arguments resembling options, while
+// options preceding it are parsed as usual.
+func TestParserParse_Remainder(t *testing.T) {
+	p := NewParser("parser")
+	run := p.AddCommand("run", "run a plugin")
+	run.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewArg("plugin", "plugin", "plugin to run"),
+		NewRemainder("args", "args", "arguments for the plugin"),
+	)
+
+	tests := []struct {
+		args    []string
+		plugin  string
+		rest    []string
+		verbose string
+	}{
+		{[]string{"run", "myplugin", "--foo", "-b"}, "myplugin", []string{"--foo", "-b"}, "false"},
+		{[]string{"run", "-v", "myplugin", "--verbose", "--", "x"}, "myplugin", []string{"--verbose", "--", "x"}, "true"},
+		{[]string{"run", "--", "-p", "--foo"}, "-p", []string{"--foo"}, "false"},
+		{[]string{"run", "myplugin"}, "myplugin", nil, "false"},
+		{[]string{"run", "myplugin", "--"}, "myplugin", []string{"--"}, "false"},
+	}
+	for _, test := range tests {
+		ns, _, err := p.Parse(test.args...)
+		if err != nil {
+			t.Errorf("An unexpected error occurred for %q: %s", test.args, err.Error())
+			continue
+		}
+		if ns.String("plugin") != test.plugin || ns.String("verbose") != test.verbose {
+			t.Errorf("Expected plugin '%s' and verbose '%s' for %q, but received: %v", test.plugin, test.verbose, test.args, ns.Mapping)
+		}
+		if rest := ns.Slice("args"); reflect.DeepEqual(rest, test.rest) == false {
+			t.Errorf("Expected remainder %q for %q, but received: %q", test.rest, test.args, rest)
+		}
+	}
+}
+
+// TestParserParse_LongNames tests the Parse method to ensure long options with
+// digits and hyphens within their names are recognized.
+func TestParserParse_LongNames(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("log-level", "logLevel", "log level").Nargs("1").Action(Store),
+		NewOption("max-retries", "maxRetries", "maximum retries").Nargs("1").Action(Store),
+		NewFlag("ipv6", "ipv6", "use IPv6"),
+	)
+
+	ns, args, err := p.Parse("--log-level", "debug", "--max-retries=3", "--ipv6", "--", "--ipv6")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("logLevel") != "debug" || ns.String("maxRetries") != "3" || ns.String("ipv6") != "true" {
+		t.Errorf("Expected logLevel 'debug', maxRetries '3', and ipv6 'true', but received: %v", ns.Mapping)
+	}
+	if len(args) != 1 || args[0] != "--ipv6" {
+		t.Errorf("Expected args [--ipv6] following the terminator, but received: %q", args)
+	}
+}
+
+// TestParserParse_EmptyArgs tests the Parse method to ensure empty arguments and
+// a lone `-` are bound to positional options, and that a trailing `--` acts as
+// the terminator rather than being returned as an argument.
+func TestParserParse_EmptyArgs(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("files", "files", "input files").Positional().Nargs("*").Action(Store),
+	)
+
+	ns, leftovers, err := p.Parse("", "-", "--", "")
+	if err != nil || len(leftovers) != 0 {
+		t.Fatalf("Expected no leftovers or error, but received: %q (%v)", leftovers, err)
+	}
+	if files := ns.Slice("files"); len(files) != 3 || files[0] != "" || files[1] != "-" || files[2] != "" {
+		t.Errorf("Expected files [\"\" \"-\" \"\"], but received: %q", files)
+	}
+
+	ns, leftovers, err = p.Parse("-v", "--")
+	if err != nil || len(leftovers) != 0 || ns.String("verbose") != "true" {
+		t.Errorf("Expected verbose 'true' and no leftovers, but received: '%s' %q (%v)", ns.String("verbose"), leftovers, err)
+	}
+}
+
+// TestParserParse_FromEnv tests the Parse method to ensure an option's value is
+// taken from the command line, then its environment variable, then its default.
+// An environment variable set to an empty string is treated as unset.
+func TestParserParse_FromEnv(t *testing.T) {
+	oldToken, hadToken := os.LookupEnv("ARGPARSE_TEST_TOKEN")
+	defer func() {
+		if hadToken {
+			os.Setenv("ARGPARSE_TEST_TOKEN", oldToken)
+		} else {
+			os.Unsetenv("ARGPARSE_TEST_TOKEN")
+		}
+	}()
+
+	p := NewParser("parser")
+	p.AddOption(NewOption("t token", "token", "api token").Nargs("1").Action(Store).Default("none").FromEnv("ARGPARSE_TEST_TOKEN"))
+
+	os.Setenv("ARGPARSE_TEST_TOKEN", "from-env")
+	if ns, _, err := p.Parse("--token", "from-cli"); err != nil || ns.String("token") != "from-cli" {
+		t.Errorf("Expected token 'from-cli', but received: '%s' (%v)", ns.String("token"), err)
+	}
+
+	if ns, _, err := p.Parse(); err != nil || ns.String("token") != "from-env" {
+		t.Errorf("Expected token 'from-env', but received: '%s' (%v)", ns.String("token"), err)
+	}
+
+	os.Setenv("ARGPARSE_TEST_TOKEN", "")
+	if ns, _, err := p.Parse(); err != nil || ns.String("token") != "none" {
+		t.Errorf("Expected token 'none', but received: '%s' (%v)", ns.String("token"), err)
+	}
+
+	os.Unsetenv("ARGPARSE_TEST_TOKEN")
+	if ns, _, err := p.Parse(); err != nil || ns.String("token") != "none" {
+		t.Errorf("Expected token 'none', but received: '%s' (%v)", ns.String("token"), err)
+	}
+}
+
+// TestParserParse_FromEnvActions tests the Parse method to ensure values taken
+// from environment variables are given to the option's action, so that appended
+// options receive a slice, counters a count, and flags a boolean, while options
+// present on the command line ignore their environment variable.
+func TestParserParse_FromEnvActions(t *testing.T) {
+	for _, name := range []string{"ARGPARSE_TEST_TAG", "ARGPARSE_TEST_VERBOSE", "ARGPARSE_TEST_CACHE", "ARGPARSE_TEST_SIZE"} {
+		old, had := os.LookupEnv(name)
+		defer func(name, old string, had bool) {
+			if had {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name, old, had)
+	}
+
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("t tag", "tags", "add a tag").Nargs("1").Action(Append).FromEnv("ARGPARSE_TEST_TAG"),
+		NewOption("v verbose", "verbose", "verbosity").Action(Count).FromEnv("ARGPARSE_TEST_VERBOSE"),
+		NewFlag("c cache", "cache", "use the cache").FromEnv("ARGPARSE_TEST_CACHE"),
+		NewOption("s size", "size", "width and height").Nargs("2").Action(Store).FromEnv("ARGPARSE_TEST_SIZE"),
+	)
+
+	os.Setenv("ARGPARSE_TEST_TAG", "from-env")
+	os.Setenv("ARGPARSE_TEST_VERBOSE", "2")
+	os.Setenv("ARGPARSE_TEST_CACHE", "yes")
+	os.Setenv("ARGPARSE_TEST_SIZE", "80 24")
+	ns, _, err := p.Parse()
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if tags := ns.Slice("tags"); reflect.DeepEqual(tags, []string{"from-env"}) == false {
+		t.Errorf("Expected tags [from-env], but received: %#v", ns.Get("tags"))
+	}
+	if ns.String("verbose") != "2" || ns.String("cache") != "true" {
+		t.Errorf("Expected verbose '2' and cache 'true', but received: '%s' '%s'", ns.String("verbose"), ns.String("cache"))
+	}
+	if size := ns.Slice("size"); reflect.DeepEqual(size, []string{"80", "24"}) == false {
+		t.Errorf("Expected size [80 24], but received: %#v", ns.Get("size"))
+	}
+
+	ns, _, err = p.Parse("-t", "a", "-t", "b")
+	if err != nil || reflect.DeepEqual(ns.Slice("tags"), []string{"a", "b"}) == false {
+		t.Errorf("Expected tags [a b], but received: %v (%v)", ns.Get("tags"), err)
+	}
+
+	os.Setenv("ARGPARSE_TEST_CACHE", "maybe")
+	expected := `-c, --cache: invalid value "maybe": expected a boolean value such as true or false`
+	if _, _, err = p.Parse(); err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
+// TestParserParse_EnvPrefix tests the Parse method to ensure that options fall
+// back to environment variables named after their long names using the parser's
+// environment prefix, after the command line and before their defaults, unless
+// they name their own variable or ignore the environment.
+func TestParserParse_EnvPrefix(t *testing.T) {
+	for _, name := range []string{"MYAPP_MAX_RETRIES", "MYAPP_OUTPUT", "MYAPP_QUIET", "MYAPP_HELP", "ARGPARSE_TEST_OUTPUT"} {
+		old, had := os.LookupEnv(name)
+		defer func(name, old string, had bool) {
+			if had {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name, old, had)
+		os.Unsetenv(name)
+	}
+
+	p := NewParser("parser").SetEnvPrefix("MYAPP")
+	p.AddOptions(
+		NewOption("r max-retries", "retries", "number of retries").Nargs("1").Action(Store).Default("3"),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).FromEnv("ARGPARSE_TEST_OUTPUT"),
+		NewFlag("q quiet", "quiet", "quiet output").NotFromEnv(),
+	)
+
+	if ns, _, err := p.Parse(); err != nil || ns.String("retries") != "3" {
+		t.Errorf("Expected retries '3', but received: '%s' (%v)", ns.String("retries"), err)
+	}
+
+	os.Setenv("MYAPP_MAX_RETRIES", "5")
+	if ns, _, err := p.Parse(); err != nil || ns.String("retries") != "5" {
+		t.Errorf("Expected retries '5', but received: '%s' (%v)", ns.String("retries"), err)
+	}
+	if ns, _, err := p.Parse("--max-retries", "7"); err != nil || ns.String("retries") != "7" {
+		t.Errorf("Expected retries '7', but received: '%s' (%v)", ns.String("retries"), err)
+	}
+
+	os.Setenv("MYAPP_OUTPUT", "derived.txt")
+	os.Setenv("ARGPARSE_TEST_OUTPUT", "explicit.txt")
+	os.Setenv("MYAPP_QUIET", "true")
+	os.Setenv("MYAPP_HELP", "true")
+	ns, _, err := p.Parse()
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("output") != "explicit.txt" {
+		t.Errorf("Expected output 'explicit.txt', but received: '%s'", ns.String("output"))
+	}
+	if ns.String("quiet") != "false" {
+		t.Errorf("Expected quiet 'false', but received: '%s'", ns.String("quiet"))
+	}
+}
+
+// TestParserWasSet tests the WasSet method to ensure only options present on the
+// command line are set, even when their value equals the default, while values
+// from the environment, the config file, or the default are not.
+func TestParserWasSet(t *testing.T) {
+	oldLevel, hadLevel := os.LookupEnv("ARGPARSE_TEST_LEVEL")
+	defer func() {
+		if hadLevel {
+			os.Setenv("ARGPARSE_TEST_LEVEL", oldLevel)
+		} else {
+			os.Unsetenv("ARGPARSE_TEST_LEVEL")
+		}
+	}()
+	os.Setenv("ARGPARSE_TEST_LEVEL", "3")
+
+	path, cleanup := writeConfig(t, `{"host": "config-host"}`)
+	defer cleanup()
+
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("port", "port", "server port").Nargs("1").Action(Store).Default("80"),
+		NewOption("level", "level", "log level").Nargs("1").Action(Store).FromEnv("ARGPARSE_TEST_LEVEL"),
+		NewOption("host", "host", "server host").Nargs("1").Action(Store),
+		NewFlag("c color", "color", "colorize output"),
+		NewOption("user", "user", "server user").Nargs("1").Action(Store).Default("nobody"),
+		NewOption("files", "files", "input files").Positional().Nargs("*").Action(Store),
+	)
+	if err := p.LoadConfig(path); err != nil {
+		t.Fatalf("Expected config file to load, but received error: %v", err)
+	}
+
+	if _, _, err := p.Parse("--port", "80", "--no-color"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	expected := map[string]bool{"port": true, "c": true, "level": false, "host": false, "user": false, "files": false, "bogus": false}
+	for name, set := range expected {
+		if p.WasSet(name) != set {
+			t.Errorf("Expected WasSet(%q) to be %t, but received: %t", name, set, !set)
+		}
+	}
+
+	if _, _, err := p.Parse("a.txt"); err != nil || p.WasSet("files") == false || p.WasSet("port") == true {
+		t.Errorf("Expected only files to be set, but received: files %t, port %t (%v)", p.WasSet("files"), p.WasSet("port"), err)
+	}
+}
+
+// TestParserResult tests the Result method to ensure the values from the last
+// parse are keyed by long name and converted to their option's type, defaults
+// are included, and unbound arguments are listed as positionals.
+func TestParserResult(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewFlag("color", "color", "colorize output"),
+		NewCounter("d", "debug", "debug level"),
+		NewOption("p port", "port", "server port").Nargs("1").Action(Store).Type(reflect.Int).Default("80"),
+		NewOption("n name", "name", "server name").Nargs("1").Action(Store),
+		NewOption("tags", "tags", "server tags").Nargs("1").Action(Append),
+		NewMap("D define", "define", "define a variable"),
+	)
+
+	if _, _, err := p.Parse("-v", "-dd", "--name", "web", "--tags", "a", "--tags", "b", "-Dk=v", "extra"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := map[string]interface{}{
+		"verbose":      true,
+		"color":        false,
+		"debug":        2,
+		"port":         80,
+		"name":         "web",
+		"tags":         []string{"a", "b"},
+		"define":       map[string]string{"k": "v"},
+		"_positionals": []string{"extra"},
+	}
+	if result := p.Result(); reflect.DeepEqual(result, expected) == false {
+		t.Errorf("Expected result %v, but received: %v", expected, result)
+	}
+}
+
+// TestParserParse_Duration tests the Parse method to ensure duration options
+// accept durations from the command line or their environment variable, fall back
+// to their default, and reject invalid durations.
+func TestParserParse_Duration(t *testing.T) {
+	oldTimeout, hadTimeout := os.LookupEnv("ARGPARSE_TEST_TIMEOUT")
+	defer func() {
+		if hadTimeout {
+			os.Setenv("ARGPARSE_TEST_TIMEOUT", oldTimeout)
+		} else {
+			os.Unsetenv("ARGPARSE_TEST_TIMEOUT")
+		}
+	}()
+	os.Unsetenv("ARGPARSE_TEST_TIMEOUT")
+
+	p := NewParser("parser")
+	p.AddOption(NewDuration("timeout", "timeout", "time to wait").Default("10s").FromEnv("ARGPARSE_TEST_TIMEOUT"))
+
+	var tests = []struct {
+		args     []string
+		expected time.Duration
+	}{
+		{[]string{"--timeout", "30s"}, 30 * time.Second},
+		{[]string{"--timeout=1h30m"}, 90 * time.Minute},
+		{[]string{}, 10 * time.Second},
+	}
+	for _, test := range tests {
+		ns, _, err := p.Parse(test.args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred for %v: %s", test.args, err.Error())
+		}
+		if d, err := ns.Duration("timeout"); err != nil || d != test.expected {
+			t.Errorf("Expected timeout %v for %v, but received: %v (%v)", test.expected, test.args, d, err)
+		}
+	}
+
+	_, _, err := p.Parse("--timeout", "30x")
+	if expected := `--timeout: invalid duration "30x"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	os.Setenv("ARGPARSE_TEST_TIMEOUT", "2m")
+	if ns, _, err := p.Parse(); err != nil || ns.String("timeout") != "2m" {
+		t.Errorf("Expected timeout '2m', but received: '%s' (%v)", ns.String("timeout"), err)
+	}
+
+	os.Setenv("ARGPARSE_TEST_TIMEOUT", "soon")
+	if _, _, err := p.Parse(); err == nil {
+		t.Error("An error was expected for an invalid environment variable but did not occur")
+	}
+}
+
+// TestParserParse_NetworkValues tests the Parse method to ensure IP address,
+// host and port, and URL options accept valid values, and reject invalid values
+// with an error naming the option and the value.
+func TestParserParse_NetworkValues(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewIP("bind", "bind", "address to bind"),
+		NewHostPort("listen", "listen", "address to listen on"),
+		NewURL("endpoint", "endpoint", "endpoint to connect to"),
+	)
+
+	ns, _, err := p.Parse("--bind", "::1", "--listen", "0.0.0.0:8080", "--endpoint", "https://host/path")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ip, err := ns.IP("bind"); err != nil || ip.String() != "::1" {
+		t.Errorf("Expected bind '::1', but received: '%v' (%v)", ip, err)
+	}
+	if hp, err := ns.HostPort("listen"); err != nil || hp.Host != "0.0.0.0" || hp.Port != "8080" {
+		t.Errorf("Expected listen '0.0.0.0:8080', but received: '%v' (%v)", hp, err)
+	}
+	if u, err := ns.URL("endpoint"); err != nil || u.Host != "host" || u.Path != "/path" {
+		t.Errorf("Expected endpoint 'https://host/path', but received: '%v' (%v)", u, err)
+	}
+
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--bind", "10.0.0.256"}, `--bind: invalid value "10.0.0.256": not an IP address`},
+		{[]string{"--listen", "0.0.0.0"}, `--listen: invalid value "0.0.0.0": missing port in address`},
+		{[]string{"--listen", "host:"}, `--listen: invalid value "host:": missing port`},
+		{[]string{"--endpoint", "host/path"}, `--endpoint: invalid value "host/path": not an absolute URL`},
+	}
+	for _, test := range tests {
+		_, _, err := p.Parse(test.args...)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", test.expected, test.args, err)
+		}
+	}
+}
+
+// TestParserParse_SizeValues tests to ensure that byte-size and percentage
+// options are retrieved as bytes and fractions, including their defaults, and
+// that invalid values are reported with the option and the offending value.
+func TestParserParse_SizeValues(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewByteSize("max-size", "max_size", "largest file to keep").Default("1MiB"),
+		NewPercent("ratio", "ratio", "share of files to sample"),
+	)
+
+	ns, _, err := p.Parse("--ratio", "75%")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if size, err := ns.ByteSize("max_size"); err != nil || size != 1048576 {
+		t.Errorf("Expected max_size 1048576, but received: %d (%v)", size, err)
+	}
+	if ratio, err := ns.Percent("ratio"); err != nil || ratio != 0.75 {
+		t.Errorf("Expected ratio 0.75, but received: %v (%v)", ratio, err)
+	}
+
+	ns, _, err = p.Parse("--max-size", "10MB")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if size, err := ns.ByteSize("max_size"); err != nil || size != 10000000 {
+		t.Errorf("Expected max_size 10000000, but received: %d (%v)", size, err)
+	}
+	if _, err := ns.Percent("ratio"); err == nil {
+		t.Errorf("Expected an error retrieving the unset ratio, but received none")
+	}
+
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--max-size", "10XB"}, `--max-size: invalid value "10XB": unknown unit "XB"`},
+		{[]string{"--max-size", "big"}, `--max-size: invalid value "big": expected a size such as 10MB or 512KiB`},
+		{[]string{"--ratio", "most"}, `--ratio: invalid value "most": expected a percentage such as 75%`},
+	}
+	for _, test := range tests {
+		_, _, err := p.Parse(test.args...)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", test.expected, test.args, err)
+		}
+	}
+}
+
+// TestParserParse_Occurrences tests to ensure that options limited to a range of
+// occurrences are accepted within the range, and are reported when present too
+// few or too many times.
+func TestParserParse_Occurrences(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("t tag", "tag", "tag to apply").Nargs("1").Action(Append).MinOccurrences(1),
+		NewOption("v verbose", "verbose", "increase verbosity").Action(Count).MaxOccurrences(3),
+		NewOption("p peer", "peer", "peer to connect to").Nargs("1").Action(Append).MinOccurrences(2).MaxOccurrences(2),
+	)
+
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-t", "a", "-p", "x", "-p", "y"}, ""},
+		{[]string{"-t", "a", "-t", "b", "-vvv", "-p", "x", "-p", "y"}, ""},
+		{[]string{"-p", "x", "-p", "y"}, "-t, --tag: requires at least 1 value"},
+		{[]string{"-t", "a", "-vvvv", "-p", "x", "-p", "y"}, "-v, --verbose: may be specified at most 3 times"},
+		{[]string{"-t", "a", "-p", "x"}, "-p, --peer: requires at least 2 values"},
+		{[]string{"-t", "a", "-p", "x", "-p", "y", "-p", "z"}, "-p, --peer: may be specified at most 2 times"},
+	}
+	for _, test := range tests {
+		_, _, err := p.Parse(test.args...)
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("An unexpected error occurred for %v: %s", test.args, err.Error())
+			}
+		} else if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", test.expected, test.args, err)
+		}
+	}
+
+	ns, _, err := p.Parse("-t", "a", "-t", "b", "-vv", "-p", "x", "-p", "y")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if tags := ns.Slice("tag"); reflect.DeepEqual(tags, []string{"a", "b"}) == false {
+		t.Errorf("Expected tags '[a b]', but received: '%v'", tags)
+	}
+	if verbose, _ := ns.Int("verbose"); verbose != 2 {
+		t.Errorf("Expected verbose 2, but received: %d", verbose)
+	}
+}
+
+// TestParserParse_IgnoreUnknown tests to ensure that, when unknown options are
+// ignored, known options still bind while unknown options are collected along
+// with their values, and that unknown options fail the parse otherwise.
+func TestParserParse_IgnoreUnknown(t *testing.T) {
+	p := NewParser("parser").SetIgnoreUnknown(true)
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewArg("input", "input", "input file"),
+	)
+
+	var tests = []struct {
+		args     []string
+		input    string
+		expected []string
+	}{
+		{[]string{"--new-flag", "x", "-o", "out", "in"}, "in", []string{"--new-flag", "x"}},
+		{[]string{"--new=x", "in", "--other"}, "in", []string{"--new=x", "--other"}},
+		{[]string{"--new", "-v", "in"}, "in", []string{"--new"}},
+		{[]string{"-vx", "1", "in"}, "in", []string{"-x", "1"}},
+		{[]string{"in", "--new"}, "in", []string{"--new"}},
+	}
+	for _, test := range tests {
+		ns, _, err := p.Parse(test.args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred for %v: %s", test.args, err.Error())
+		}
+		if ns.String("input") != test.input {
+			t.Errorf("Expected input '%s' for %v, but received: '%s'", test.input, test.args, ns.String("input"))
+		}
+		if reflect.DeepEqual(p.Unknown(), test.expected) == false {
+			t.Errorf("Expected unknown options '%v' for %v, but received: '%v'", test.expected, test.args, p.Unknown())
+		}
+	}
+
+	ns, _, err := p.Parse("--new-flag", "x", "-vo", "out", "in")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("output") != "out" || ns.String("verbose") != "true" {
+		t.Errorf("Expected output 'out' and verbose 'true', but received: '%s' and '%s'", ns.String("output"), ns.String("verbose"))
+	}
+
+	p.SetIgnoreUnknown(false)
+	if _, _, err := p.Parse("--new-flag", "in"); err == nil {
+		t.Errorf("Expected an error for an unknown option, but received none")
+	} else if p.Unknown() != nil {
+		t.Errorf("Expected no unknown options, but received: '%v'", p.Unknown())
+	}
+}
+
+// TestParserMustParse tests to ensure that MustParse returns the parsed values,
+// exits with status 2 after outputting an error along with the usage, and exits
+// with status 0 after showing the help text.
+func TestParserMustParse(t *testing.T) {
+	var output, errOutput bytes.Buffer
+	codes := []int{}
+	p := NewParser("parser").Prog("prog").SetOutput(&output).SetErrorOutput(&errOutput)
+	p.SetExitFunc(func(code int) { codes = append(codes, code) })
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+
+	ns, _ := p.MustParse("-v")
+	if len(codes) != 0 || ns == nil || ns.String("verbose") != "true" {
+		t.Fatalf("Expected verbose 'true' without exiting, but exited with: %v", codes)
+	}
+
+	if ns, args := p.MustParse("--unknown"); ns != nil || args != nil {
+		t.Errorf("Expected no values after exiting, but received: '%v' and '%v'", ns, args)
+	}
+	expected := "prog: error: invalid option \"unknown\"\nusage: prog [-h] [-v]\n"
+	if len(codes) != 1 || codes[0] != 2 {
+		t.Errorf("Expected exit status 2, but received: %v", codes)
+	} else if errOutput.String() != expected {
+		t.Errorf("Expected error output:\n%s\nbut received:\n%s", expected, errOutput.String())
+	}
+
+	p.MustParse("--help")
+	if len(codes) != 2 || codes[1] != 0 {
+		t.Errorf("Expected exit status 0, but received: %v", codes)
+	} else if strings.Contains(output.String(), "verbose output") == false {
+		t.Errorf("Expected the help text to be output, but received: '%s'", output.String())
+	}
+}
+
+// TestParserParseContext tests to ensure that a canceled context stops the parse
+// with the context's error before any further actions are run, and that actions
+// can retrieve the context while parsing.
+func TestParserParseContext(t *testing.T) {
+	var ran []string
+	ctx, cancel := context.WithCancel(context.Background())
+	current := ctx
+	record := func(p *Parser, f *Option, args ...string) ([]string, error) {
+		if p.Context() != current {
+			t.Errorf("Expected the parse context within the %s action", f.DestName)
+		}
+		ran = append(ran, f.DestName)
+		if f.DestName == "fetch" {
+			cancel()
+		}
+		return args, nil
+	}
+
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("a", "a", "first").Action(record),
+		NewOption("fetch", "fetch", "fetch from the network").Action(record),
+		NewOption("b", "b", "last").Action(record),
+	)
+
+	_, _, err := p.ParseContext(ctx, "-a", "--fetch", "-b")
+	if err != context.Canceled {
+		t.Errorf("Expected error '%v', but received: '%v'", context.Canceled, err)
+	}
+	if reflect.DeepEqual(ran, []string{"a", "fetch"}) == false {
+		t.Errorf("Expected the actions '[a fetch]' to run, but received: '%v'", ran)
+	}
+
+	ran = nil
+	if _, _, err := p.ParseContext(ctx, "-a"); err != context.Canceled || ran != nil {
+		t.Errorf("Expected error '%v' without running any actions, but received: '%v' after %v", context.Canceled, err, ran)
+	}
+
+	current = context.Background()
+	if _, _, err := p.ParseContext(current, "-b"); err != nil {
+		t.Errorf("An unexpected error occurred: %s", err.Error())
+	} else if p.Context() != context.Background() {
+		t.Errorf("Expected the background context after parsing")
+	}
+
+	// A subcommand's actions receive the context, which is cleared afterwards.
+	command := p.AddCommand("sync", "synchronize")
+	command.AddOption(NewOption("now", "now", "synchronize now").Action(record))
+	ran = nil
+	current, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if _, _, err := p.ParseContext(current, "sync", "--now"); err != nil || reflect.DeepEqual(ran, []string{"now"}) == false {
+		t.Errorf("Expected the action '[now]' to run, but received: '%v' (%v)", ran, err)
+	} else if command.Context() != context.Background() {
+		t.Errorf("Expected the subcommand's background context after parsing")
+	}
+
+	// Validators added by ValidateContext are provided the context, and see
+	// its cancellation.
+	current, cancel = context.WithCancel(context.Background())
+	validated := NewParser("parser")
+	validated.AddOption(NewOption("u url", "url", "url to check").Nargs("1").Action(Store).ValidateContext(func(ctx context.Context, arg string) error {
+		if ctx != current {
+			t.Error("Expected the parse context within the validator")
+		}
+		cancel()
+		return ctx.Err()
+	}))
+	expected := `-u, --url: invalid value "x": context canceled`
+	if _, _, err := validated.ParseContext(current, "--url", "x"); err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
+// TestParserParseArgs tests the ParseArgs method to ensure that the arguments
+// provided by os.Args are parsed, and the program name is taken from its path.
+func TestParserParseArgs(t *testing.T) {
+	oldArgs := os.Args
+	os.Args = []string{"/usr/local/bin/my_prog", "-o", "out.txt", "file.txt"}
+	defer func() { os.Args = oldArgs }()
+
+	p := &Parser{}
+	p.AddOption(NewOption("o output", "output", "output file").Nargs("1").Action(Store))
+
+	ns, args, err := p.ParseArgs()
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if ns.String("output") != "out.txt" {
+		t.Errorf("Expected output 'out.txt', but received: '%s'", ns.String("output"))
+	}
+
+	if len(args) != 1 || args[0] != "file.txt" {
+		t.Errorf("Expected remaining arguments: '[file.txt]' but received: '%v'", args)
+	}
+
+	if p.ProgramName != "my_prog" {
+		t.Errorf("Expected program name 'my_prog', but received: '%s'", p.ProgramName)
+	}
+}
+
+// TestParserPath tests the Path method to ensure that providing a filepath will
+// result in updating the parser's program name.
+func TestParserPath(t *testing.T) {
+	path := "/usr/local/bin/my_prog"
+	expected := "my_prog"
+
+	p := Parser{}
+	if len(p.ProgramName) != 0 {
+		t.Errorf("Parser program name should be an empty string, but is: %s", p.ProgramName)
+	}
+
+	p.Path(path)
+	if p.ProgramName != expected {
+		t.Errorf(
+			"The parser's ProgramName '%s' does not match the expected name: '%s'",
+			p.ProgramName,
+			expected,
+		)
+	}
+}
+
+// TestParserProg tests the Prog method to ensure that providing a program name
+// will result in updating the parser's program name string.
+func TestParserProg(t *testing.T) {
+	name := "awesome_go_prog"
+
+	p := Parser{}
+	if len(p.ProgramName) != 0 {
+		t.Errorf("Parser program name should be an empty string, but is: %s", p.ProgramName)
+	}
+
+	p.Prog(name)
+	if p.ProgramName != name {
+		t.Errorf(
+			"The parser's ProgramName '%s' does not match the expected name: '%s'",
+			p.ProgramName,
+			name,
+		)
+	}
+}
+
+// TestParserUsage tests the Usage method to ensure that providing a usage string
+// will result in updating the parser's usage string.
+func TestParserUsage(t *testing.T) {
+	usage := "do stuff to accomplish things"
+
+	p := Parser{}
+	if len(p.UsageText) != 0 {
+		t.Errorf("Parser usage text should be an empty string, but is: %s", p.UsageText)
+	}
+
+	p.Usage(usage)
+	if p.UsageText != usage {
+		t.Errorf(
+			"The parser's UsageText '%s' does not match the expected usage: '%s'",
+			p.UsageText,
+			usage,
+		)
+	}
+}
+
+// TestParserGetHelp_Negatable tests that negatable flags are listed within the
+// help text with a `[no-]` hint on their long names, while other flags are
+// listed unchanged.
+func TestParserGetHelp_Negatable(t *testing.T) {
+	p := NewParser("parser").Prog("prog").SetWidth(80)
+	p.AddOptions(
+		NewFlag("c color", "color", "colorize output"),
+		NewFlag("verbose", "verbose", "verbose output").NotNegatable(),
+	)
+
+	help := p.GetHelp()
+	for _, line := range []string{"  -c, --[no-]color  colorize output\n", "  --verbose         verbose output\n"} {
+		if strings.Contains(help, line) == false {
+			t.Errorf("Expected the help text to contain '%s', but received:\n%s", strings.TrimSpace(line), help)
+		}
+	}
+}
+
+// TestParserPrintHelp tests the PrintHelp method to ensure the parser will write
+// its help text to the provided writer, with option descriptions aligned and
+// wrapped to the width of the screen.
+func TestParserPrintHelp(t *testing.T) {
+	oldColumns, hadColumns := os.LookupEnv("COLUMNS")
+	defer func() {
+		if hadColumns {
+			os.Setenv("COLUMNS", oldColumns)
+		} else {
+			os.Unsetenv("COLUMNS")
+		}
+	}()
+	os.Setenv("COLUMNS", "40")
+
+	p := NewParser("program description").Prog("prog")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "Enable verbose output for every single operation"),
+		NewFlag("n", "dry", "Dry run"),
+	)
+
+	var buf bytes.Buffer
+	p.PrintHelp(&buf)
+
+	expected := []string{
+		"usage: prog [-h] [-v] [-n]",
+		"",
+		"program description",
+		"",
+		"optional arguments:",
+		"  -h, --help          Show program help",
+		"  -v, --[no-]verbose  Enable verbose",
+		"                      output for every",
+		"                      single operation",
+		"  -n                  Dry run",
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected help:\n%s\nbut received:\n%s", strings.Join(expected, "\n"), buf.String())
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected line: '%s' but received: '%s'", expected[i], line)
+		}
+	}
+}
+
+// TestParserAbbreviations tests that long options can be abbreviated to an
+// unambiguous prefix, and that strict matching can be enabled instead.
+func TestParserAbbreviations(t *testing.T) {
+	newParser := func() *Parser {
+		p := NewParser("parser")
+		p.AddOptions(
+			NewFlag("verbose", "verbose", "verbose output"),
+			NewFlag("version", "version", "show version"),
+			NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		)
+		return p
+	}
+
+	ns, _, err := newParser().Parse("--verbose", "--out", "file.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("output") != "file.txt" {
+		t.Errorf("Options were not resolved from their prefixes: %v", ns.Mapping)
+	}
+
+	ns, _, err = newParser().Parse("--verb", "--outp=file.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("output") != "file.txt" {
+		t.Errorf("Options were not resolved from their prefixes: %v", ns.Mapping)
+	}
+
+	_, _, err = newParser().Parse("--ver")
+	if _, ok := err.(AmbiguousOptionErr); ok == false {
+		t.Fatalf("Expected an AmbiguousOptionErr, but received: %v", err)
+	}
+	expected := `ambiguous option "--ver" (could be: --verbose, --version)`
+	if err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%s'", expected, err.Error())
+	}
+
+	_, _, err = newParser().Parse("--quiet")
+	if _, ok := err.(InvalidOptionErr); ok == false {
+		t.Errorf("Expected an InvalidOptionErr, but received: %v", err)
+	}
+
+	_, _, err = newParser().SetAllowAbbreviation(false).Parse("--verb")
+	if _, ok := err.(InvalidOptionErr); ok == false {
+		t.Errorf("Expected an InvalidOptionErr with abbreviations disabled, but received: %v", err)
+	}
+}
+
+// TestParserAddOption_Collisions tests that registering a public name already
+// used by another option panics, naming the option and both help texts, while an
+// automatically added help option yields the name instead.
+func TestParserAddOption_Collisions(t *testing.T) {
+	expectPanic := func(expected string, register func()) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Expected a panic with '%s', but none occurred", expected)
+			} else if r != expected {
+				t.Errorf("Expected a panic with '%s', but received: '%v'", expected, r)
+			}
+		}()
+		register()
+	}
+
+	expectPanic(`option name '-v' of "Show program version" is already used by "verbose output"`, func() {
+		NewParser("parser").AddOption(NewFlag("v verbose", "verbose", "verbose output")).AddVersion()
+	})
+
+	expectPanic(`option name '--verbose' of "louder output" is already used by "verbose output"`, func() {
+		NewParser("parser").AddOptions(
+			NewFlag("v verbose", "verbose", "verbose output"),
+			NewFlag("verbose", "loud", "louder output"),
+		)
+	})
+
+	expectPanic(`option name '-h' of "host name" is already used by "Show program help"`, func() {
+		NewParser("parser").AddHelp().AddOption(NewOption("h host", "host", "host name"))
+	})
+
+	p := NewParser("parser")
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+	p.GetHelp()
+	p.AddOption(NewOption("h host", "host", "host name").Nargs("1").Action(Store))
+
+	ns, _, err := p.Parse("-h", "example.com")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("host") != "example.com" {
+		t.Errorf("Expected host 'example.com', but received: '%s'", ns.String("host"))
+	}
+	if option, err := p.GetOption("help"); err != nil || option != p.helpOption {
+		t.Errorf("Expected --help to remain the help option, but received: %v", err)
+	}
+}
+
+// TestParserCaseInsensitive tests that long and short options can separately be
+// matched regardless of case, and that matching is case-sensitive by default.
+func TestParserCaseInsensitive(t *testing.T) {
+	newParser := func() *Parser {
+		p := NewParser("parser")
+		p.AddOptions(
+			NewFlag("v verbose", "verbose", "verbose output"),
+			NewFlag("color", "color", "colorize output"),
+			NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		)
+		return p
+	}
+
+	if _, _, err := newParser().Parse("--Verbose"); err == nil {
+		t.Error("An error was expected for a long option of the wrong case")
+	}
+	if _, _, err := newParser().Parse("-V"); err == nil {
+		t.Error("An error was expected for a short option of the wrong case")
+	}
+
+	ns, _, err := newParser().SetCaseInsensitive(true).Parse("--VERBOSE", "--Output", "File.txt", "--No-Color")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("output") != "File.txt" || ns.String("color") != "false" {
+		t.Errorf("Long options were not matched regardless of case: %v", ns.Mapping)
+	}
+	if _, _, err := newParser().SetCaseInsensitive(true).Parse("-V"); err == nil {
+		t.Error("An error was expected for a short option of the wrong case")
+	}
+
+	ns, _, err = newParser().SetShortCaseInsensitive(true).Parse("-V", "-O", "File.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("output") != "File.txt" {
+		t.Errorf("Short options were not matched regardless of case: %v", ns.Mapping)
+	}
+	if _, _, err := newParser().SetShortCaseInsensitive(true).Parse("--Verbose"); err == nil {
+		t.Error("An error was expected for a long option of the wrong case")
+	}
+}
+
+// TestParserSingleDashLong tests that a single-dash argument naming a long option
+// in full is treated as that long option when enabled, and as a cluster of short
+// options otherwise.
+func TestParserSingleDashLong(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("verbose", "verbose", "verbose output"),
+		NewOption("output", "output", "output file").Nargs("1").Action(Store),
+	)
+	for _, name := range []string{"v", "e", "r", "b", "o", "s"} {
+		p.AddOption(NewFlag(name, name, "a short flag"))
+	}
+
+	ns, _, err := p.Parse("-verbose")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "false" || ns.String("v") != "true" || ns.String("s") != "true" {
+		t.Errorf("Expected '-verbose' to be a cluster of short options, but received: %v", ns.Mapping)
+	}
+
+	p.SetSingleDashLong(true)
+	ns, args, err := p.Parse("-verbose", "-output", "out.txt", "-verb")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("output") != "out.txt" {
+		t.Errorf("Expected '-verbose' and '-output' to be long options, but received: %v", ns.Mapping)
+	}
+	if ns.String("e") != "true" || ns.String("s") != "false" || len(args) != 0 {
+		t.Errorf("Expected '-verb' to be a cluster of short options, but received: %v", ns.Mapping)
+	}
+
+	if ns, _, err = p.Parse("-output=out.txt"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("output") != "out.txt" {
+		t.Errorf("Expected output 'out.txt', but received: '%s'", ns.String("output"))
+	}
+}
+
+// TestParserSlashPrefix tests that options can be prefixed by `/`, with values
+// attached using `:`, only when enabled, and that other arguments beginning with
+// `/` remain arguments.
+func TestParserSlashPrefix(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("out", "out", "output file").Nargs("1").Action(Store),
+		NewArg("input", "input", "the input file"),
+	)
+
+	if _, _, err := p.Parse("/v", "/out:file.txt"); err == nil {
+		t.Error("Expected `/` prefixed arguments to be positionals when disabled, but no error occurred")
+	}
+
+	p.SetSlashPrefix(true)
+	ns, args, err := p.Parse("/v", "/out:file.txt", "/usr/input.txt")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("out") != "file.txt" {
+		t.Errorf("Expected verbose 'true' and out 'file.txt', but received: %v", ns.Mapping)
+	}
+	if ns.String("input") != "/usr/input.txt" || len(args) != 0 {
+		t.Errorf("Expected input '/usr/input.txt', but received: '%s' %v", ns.String("input"), args)
+	}
+
+	if ns, _, err = p.Parse("/verbose", "--out=file.txt", "-v", "in.txt"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("verbose") != "true" || ns.String("out") != "file.txt" {
+		t.Errorf("Expected dash prefixed options to remain valid, but received: %v", ns.Mapping)
+	}
+}
+
+// TestParserParse_OptionAsValue tests that an option expecting a value is not
+// given another option as its value, unless that option is attached explicitly.
+func TestParserParse_OptionAsValue(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	_, _, err := p.Parse("--output")
+	if _, ok := err.(TooFewArgsErr); ok == false {
+		t.Errorf("Expected a TooFewArgsErr for a missing value, but received: %v", err)
+	}
+
+	tests := map[string][]string{
+		`-o, --output: expected a value, but received the option "--verbose" (use "--output=--verbose" to pass it as the value)`: {"--output", "--verbose"},
+		`-o, --output: expected a value, but received the option "-v" (use "-o-v" to pass it as the value)`:                      {"-o", "-v"},
+	}
+	for expected, args := range tests {
+		if _, _, err := p.Parse(args...); err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", expected, args, err)
+		}
+	}
+
+	for _, args := range [][]string{{"--output=--verbose"}, {"-o--verbose"}} {
+		ns, _, err := p.Parse(args...)
+		if err != nil || ns.String("output") != "--verbose" || ns.String("verbose") != "false" {
+			t.Errorf("Expected output '--verbose' for %v, but received: %v (%v)", args, ns, err)
+		}
+	}
+}
+
+// TestParserPlusFlags tests that `+` prefixed short names invert boolean flags
+// when plus flags are allowed, and are otherwise positional arguments.
+func TestParserPlusFlags(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("x", "trace", "trace commands"),
+		NewOption("e", "noexec", "do not execute").Nargs("0").Action(StoreFalse).Default("true"),
+		NewCounter("v", "verbose", "verbosity"),
+	)
+
+	ns, args, err := p.Parse("-x", "+x", "+v")
+	if err != nil || ns.String("trace") != "true" || len(args) != 2 || args[0] != "+x" || args[1] != "+v" {
+		t.Errorf("Expected `+` prefixed arguments to be positionals when disabled, but received: %v %v (%v)", ns, args, err)
+	}
+
+	p.SetAllowPlusFlags(true)
+	tests := map[string][]string{
+		"false": {"-x", "+x"},
+		"true":  {"+x", "-x"},
+	}
+	for expected, allArgs := range tests {
+		ns, args, err := p.Parse(allArgs...)
+		if err != nil || len(args) != 0 || ns.String("trace") != expected {
+			t.Errorf("Expected trace '%s' for %v, but received: '%s' %v (%v)", expected, allArgs, ns.String("trace"), args, err)
+		}
+	}
+
+	ns, args, err = p.Parse("-e", "+e", "+v", "--", "+x")
+	if err != nil || ns.String("noexec") != "true" || ns.String("trace") != "false" {
+		t.Errorf("Expected noexec 'true' and trace 'false', but received: %v (%v)", ns, err)
+	}
+	if len(args) != 2 || args[0] != "+v" || args[1] != "+x" {
+		t.Errorf("Expected non-flags and terminated arguments to remain positionals, but received: %v", args)
+	}
+
+	// A `+` prefixed name inverts the same flag as its `-` prefixed name,
+	// regardless of whether names are case-insensitive.
+	p = NewParser("parser").SetAllowPlusFlags(true).SetCaseInsensitive(true)
+	p.AddOptions(
+		NewFlag("x", "trace", "trace commands"),
+		NewFlag("X", "extended", "extended output"),
+	)
+	ns, _, err = p.Parse("-x", "-X", "+X")
+	if err != nil || ns.String("trace") != "true" || ns.String("extended") != "false" {
+		t.Errorf("Expected trace 'true' and extended 'false', but received: %v (%v)", ns, err)
+	}
+
+	p = NewParser("parser").SetAllowPlusFlags(true).SetShortCaseInsensitive(true)
+	p.AddOption(NewFlag("x", "trace", "trace commands"))
+	ns, _, err = p.Parse("-x", "+X")
+	if err != nil || ns.String("trace") != "false" {
+		t.Errorf("Expected trace 'false', but received: %v (%v)", ns, err)
+	}
+}
+
+// TestParserSetInterspersed tests that options and positional arguments can be
+// interleaved by default, while the first positional argument ends the options
+// when arguments are not interspersed.
+func TestParserSetInterspersed(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		NewOption("files", "files", "input files").Positional().Nargs("*").Action(Store),
+	)
+
+	ns, _, err := p.Parse("file", "-v")
+	if err != nil || ns.String("verbose") != "true" || reflect.DeepEqual(ns.Slice("files"), []string{"file"}) == false {
+		t.Errorf("Expected verbose 'true' and files [file], but received: %v (%v)", ns, err)
+	}
+
+	p.SetInterspersed(false)
+	ns, _, err = p.Parse("-o", "out.txt", "file", "-v", "--", "-x")
+	if err != nil || ns.String("verbose") != "false" || ns.String("output") != "out.txt" {
+		t.Fatalf("Expected verbose 'false' and output 'out.txt', but received: %v (%v)", ns, err)
+	}
+	if files := ns.Slice("files"); reflect.DeepEqual(files, []string{"file", "-v", "--", "-x"}) == false {
+		t.Errorf("Expected files [file -v -- -x], but received: %v", files)
+	}
+
+	ns, _, err = p.Parse("-v", "--", "-o")
+	if err != nil || ns.String("verbose") != "true" || reflect.DeepEqual(ns.Slice("files"), []string{"-o"}) == false {
+		t.Errorf("Expected verbose 'true' and files [-o], but received: %v (%v)", ns, err)
+	}
+
+	// A parser not created by NewParser intersperses its arguments as well.
+	p = &Parser{}
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("files", "files", "input files").Positional().Nargs("*").Action(Store),
+	)
+	ns, _, err = p.Parse("file", "-v")
+	if err != nil || ns.String("verbose") != "true" || reflect.DeepEqual(ns.Slice("files"), []string{"file"}) == false {
+		t.Errorf("Expected verbose 'true' and files [file] for a zero parser, but received: %v (%v)", ns, err)
+	}
+}
+
+// TestParserStopEarly tests that parsing stops at the first positional argument,
+// unrecognized option, or `--`, returning the remaining arguments verbatim.
+func TestParserStopEarly(t *testing.T) {
+	newParser := func() *Parser {
+		p := NewParser("wrapper").SetStopEarly(true)
+		p.AddOptions(
+			NewFlag("v verbose", "verbose", "verbose output"),
+			NewOption("o output", "output", "output file").Nargs("1").Action(Store),
+		)
+		return p
+	}
+
+	tests := [][][]string{
+		{{"-v", "--", "inner", "--inner-flag"}, {"inner", "--inner-flag"}},
+		{{"-v", "-o", "out.txt", "inner", "-v", "--", "x"}, {"inner", "-v", "--", "x"}},
+		{{"-v", "--inner-flag=A", "inner"}, {"--inner-flag=A", "inner"}},
+		{{"-v"}, {}},
+	}
+	for _, test := range tests {
+		ns, args, err := newParser().Parse(test[0]...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred for %v: %s", test[0], err.Error())
+		}
+		if ns.String("verbose") != "true" {
+			t.Errorf("Expected verbose 'true' for %v, but received: '%s'", test[0], ns.String("verbose"))
+		}
+		if len(args) != len(test[1]) {
+			t.Errorf("Expected args %q for %v, but received: %q", test[1], test[0], args)
+			continue
+		}
+		for i := range args {
+			if args[i] != test[1][i] {
+				t.Errorf("Expected args %q for %v, but received: %q", test[1], test[0], args)
+				break
+			}
+		}
+	}
+}
+
+// TestParserSuggestions tests that an unknown option suggests the closest long
+// option name, as long as it is a near match.
+func TestParserSuggestions(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("verbose", "verbose", "verbose output"),
+		NewFlag("q quiet", "quiet", "quiet output"),
+	)
+
+	_, _, err := p.Parse("--verbsoe")
+	expected := `invalid option "verbsoe" (did you mean --verbose?)`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	_, _, err = p.Parse("--silent")
+	expected = `invalid option "silent"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
+// TestParserUnknownCluster tests that a cluster of short options which are all
+// unknown is reported as a single invalid option, suggesting a long option it
+// may have been intended as, while the unknown options within a cluster which
+// includes a known option are reported individually.
+func TestParserUnknownCluster(t *testing.T) {
+	p := NewParser("parser")
+	_, _, err := p.Parse("-xyz")
+	if expected := `invalid option "-xyz"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+	if invalid, ok := err.(InvalidOptionErr); ok == false || invalid.Index != 0 || invalid.Token != "-xyz" {
+		t.Errorf("Expected an InvalidOptionErr for the argument '-xyz', but received: %#v", err)
+	}
+
+	p.AddOptions(
+		NewFlag("v", "verbose", "verbose output"),
+		NewFlag("quiet", "quiet", "quiet output"),
+	)
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-xyz"}, `invalid option "-xyz"`},
+		{[]string{"-quiet"}, `invalid option "-quiet" (did you mean --quiet?)`},
+		{[]string{"-vyz"}, "invalid option \"y\"\ninvalid option \"z\""},
+		{[]string{"-ab", "-cd"}, "invalid option \"-ab\"\ninvalid option \"-cd\""},
+	}
+	for _, test := range tests {
+		_, _, err := p.Parse(test.args...)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", test.expected, test.args, err)
+		}
+	}
+
+	p.SetIgnoreUnknown(true)
+	if _, _, err := p.Parse("-xyz", "value", "-vyz"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	} else if expected := []string{"-xyz", "value", "-y", "-z"}; reflect.DeepEqual(p.Unknown(), expected) == false {
+		t.Errorf("Expected unknown options '%v', but received: '%v'", expected, p.Unknown())
+	}
+}
+
+// TestParserNegation tests that negatable flags are set to false by their
+// `no-` prefixed long names, with the last occurrence taking precedence.
+func TestParserNegation(t *testing.T) {
+	newParser := func() *Parser {
+		p := NewParser("parser")
+		p.AddOptions(
+			NewFlag("c color", "color", "colorize output"),
+			NewFlag("dry-run", "dryRun", "dry run").NotNegatable(),
+			NewFlag("ipv6", "ipv6", "use IPv6"),
+			NewFlag("log-color", "logColor", "colorize logs"),
+		)
+		return p
+	}
+
+	tests := map[string][]string{
+		"false": {"--color", "--no-color"},
+		"true":  {"--no-color", "--color"},
+	}
+	for expected, args := range tests {
+		ns, _, err := newParser().Parse(args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred: %s", err.Error())
+		}
+		if ns.String("color") != expected {
+			t.Errorf("Expected color '%s' for %v, but received: '%s'", expected, args, ns.String("color"))
+		}
+	}
+
+	// Negated names contain a hyphen, as may the flag's name, along with digits.
+	ns, _, err := newParser().Parse("--ipv6", "--log-color", "--no-ipv6", "--no-log-color")
+	if err != nil || ns.String("ipv6") != "false" || ns.String("logColor") != "false" {
+		t.Errorf("Expected ipv6 and logColor 'false', but received: %v (%v)", ns, err)
+	}
+
+	if _, _, err := newParser().Parse("--no-dry-run"); err == nil {
+		t.Error("An error was expected for a flag which is not negatable")
+	}
+
+	if _, _, err := newParser().Parse("--no-color=yes"); err == nil {
+		t.Error("An error was expected for a negated flag with a value")
+	}
+}
+
+// TestParserTerminator tests that arguments following `--` reach positional
+// options literally, even when they look like options.
+func TestParserTerminator(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("x", "x", "an x flag"),
+		NewArg("files", "files", "files").Nargs("*"),
+	)
+
+	ns, _, err := p.Parse("--", "--not-a-flag", "-x")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	files := ns.Slice("files")
+	if len(files) != 2 || files[0] != "--not-a-flag" || files[1] != "-x" {
+		t.Errorf("Expected files '[--not-a-flag -x]', but received: %v", files)
+	}
+	if ns.String("x") != "false" {
+		t.Errorf("Expected x 'false', but received: '%s'", ns.String("x"))
+	}
+}
+
+// TestParserParse_ErrorIndex tests that an invalid option reports the index and
+// text of the argument containing it, including within a subcommand.
+func TestParserParse_ErrorIndex(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOption(NewOption("o output", "output", "output file").Nargs("1").Action(Store))
+
+	var invalid InvalidOptionErr
+	_, _, err := p.Parse("-o", "out.txt", "file.txt", "--bogus")
+	if errors.As(err, &invalid) == false || invalid.Index != 3 || invalid.Token != "--bogus" {
+		t.Errorf("Expected an InvalidOptionErr at index 3, but received: '%v' (%+v)", err, invalid)
+	}
+	if expected := `invalid option "bogus"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+
+	add := p.AddCommand("add", "Add a file")
+	add.AddOption(NewFlag("f force", "force", "force adding"))
+
+	_, _, err = p.Parse("-o", "out.txt", "add", "-fx")
+	if errors.As(err, &invalid) == false || invalid.Index != 3 || invalid.Token != "-fx" {
+		t.Errorf("Expected an InvalidOptionErr at index 3, but received: '%v' (%+v)", err, invalid)
+	}
+}
+
+// TestParserParse_Errors tests that every independent error encountered while
+// parsing is reported together, in the order they occurred.
+func TestParserParse_Errors(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewOption("mode", "mode", "the mode").Nargs("1").Action(Store).Choices("fast", "slow"),
+		NewOption("name", "name", "a name").Nargs("1").Action(Store).Required(),
+		NewFlag("v verbose", "verbose", "verbose output"),
+	)
+
+	_, _, err := p.Parse("--bogus", "--mode", "medium", "-v")
+	errs, ok := err.(ParseErrors)
+	if ok == false {
+		t.Fatalf("Expected ParseErrors, but received: %v", err)
+	}
+
+	if len(errs.Errors()) != 3 {
+		t.Fatalf("Expected 3 errors, but received: %v", errs.Errors())
+	}
+	if _, ok := errs.Errors()[0].(InvalidOptionErr); ok == false {
+		t.Errorf("Expected an InvalidOptionErr, but received: %v", errs.Errors()[0])
+	}
+	if _, ok := errs.Errors()[1].(InvalidChoiceErr); ok == false {
+		t.Errorf("Expected an InvalidChoiceErr, but received: %v", errs.Errors()[1])
+	}
+	if _, ok := errs.Errors()[2].(MissingOptionErr); ok == false {
+		t.Errorf("Expected a MissingOptionErr, but received: %v", errs.Errors()[2])
+	}
+
+	expected := join("\n", errs.Errors()[0].Error(), errs.Errors()[1].Error(), errs.Errors()[2].Error())
+	if err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%s'", expected, err.Error())
+	}
+}
+
+// TestParserGroups tests that mutually exclusive groups, and groups requiring
+// exactly one of their options, are enforced when parsing, and that groups
+// naming unknown options panic when added.
+func TestParserGroups(t *testing.T) {
+	newParser := func() *Parser {
+		p := NewParser("parser")
+		p.AddOptions(
+			NewFlag("q quiet", "quiet", "quiet output"),
+			NewFlag("v verbose", "verbose", "verbose output"),
+			NewOption("input", "input", "input file").Nargs("1").Action(Store),
+			NewFlag("stdin", "stdin", "read from stdin"),
+		)
+		p.MutuallyExclusive("quiet", "verbose").MutuallyExclusive("quiet", "stdin")
+		p.RequiredOneOf("--input", "--stdin")
+		return p
+	}
+
+	if _, _, err := newParser().Parse("-v", "--stdin"); err != nil {
+		t.Errorf("An unexpected error occurred: %s", err.Error())
+	}
+
+	// A negated flag is not used, unless it is given again afterwards.
+	if _, _, err := newParser().Parse("--no-quiet", "--verbose", "--stdin"); err != nil {
+		t.Errorf("An unexpected error occurred: %s", err.Error())
+	}
+	if _, _, err := newParser().Parse("--no-stdin", "--verbose"); err == nil || err.Error() != "exactly one of --input, --stdin is required" {
+		t.Errorf("Expected a required group error for a negated flag, but received: '%v'", err)
+	}
+	if _, _, err := newParser().Parse("--no-quiet", "--quiet", "-v", "--stdin"); err == nil || err.Error() != "--quiet and --verbose are mutually exclusive" {
+		t.Errorf("Expected a mutually exclusive error for a flag given again, but received: '%v'", err)
+	}
+
+	tests := map[string][]string{
+		"--quiet and --verbose are mutually exclusive": {"-v", "-q", "--stdin"},
+		"--quiet and --stdin are mutually exclusive":   {"--quiet", "--stdin"},
+		"--input and --stdin are mutually exclusive":   {"--input", "file", "--stdin"},
+		"exactly one of --input, --stdin is required":  {"--verbose"},
+	}
+	for expected, args := range tests {
+		_, _, err := newParser().Parse(args...)
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s' for %v, but received: '%v'", expected, args, err)
+		}
+	}
+
+	// A group naming an unknown option panics when it is added.
+	for _, register := range []func(*Parser){
+		func(p *Parser) { p.MutuallyExclusive("quiet", "verbsoe") },
+		func(p *Parser) { p.RequiredOneOf("--input", "--verbsoe") },
+	} {
+		func() {
+			expected := "option name '--verbsoe' of group does not match any option"
+			defer func() {
+				if r := recover(); r != expected {
+					t.Errorf("Expected a panic with '%s', but received: '%v'", expected, r)
+				}
+			}()
+			register(newParser())
+		}()
+	}
+}
+
+// TestParserReset tests that parsing multiple sets of arguments with the same
+// parser does not carry values over from one parse to the next.
+func TestParserReset(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewOption("I include", "include", "include path").Nargs("1").Action(Append),
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).Default("out.txt"),
+	)
+	p.AddCommand("build", "build the project")
+
+	first, _, err := p.Parse("-v", "-I", "a", "-I", "b", "-o", "first.txt", "build")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	second, _, err := p.Parse("-I", "c")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	if second.String("verbose") != "false" || second.String("output") != "out.txt" || second.KeyExists("command") {
+		t.Errorf("Values from the first parse leaked into the second: %v", second.Mapping)
+	}
+	if includes := second.Slice("include"); len(includes) != 1 || includes[0] != "c" {
+		t.Errorf("Expected includes '[c]', but received: %v", includes)
+	}
+	if first.String("output") != "first.txt" || len(first.Slice("include")) != 2 {
+		t.Errorf("The first parse's values were modified by the second: %v", first.Mapping)
+	}
+
+	p.Reset()
+	if p.Namespace.String("output") != "out.txt" || len(p.Options) != 4 {
+		t.Errorf("Reset did not restore defaults while keeping options: %v", p.Namespace.Mapping)
+	}
+}
+
+// TestParserSeparators tests that long options can be separated from their values
+// by any of the parser's configured separators.
+func TestParserSeparators(t *testing.T) {
+	p := NewParser("parser").SetSeparators("=:")
+	p.AddOptions(
+		NewOption("foo", "foo", "a foo option").Nargs("1").Action(Store),
+		NewOption("bar", "bar", "a bar option").Nargs("1").Action(Store),
+	)
+
+	ns, _, err := p.Parse("--foo:bar:baz", "--bar=1")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("foo") != "bar:baz" || ns.String("bar") != "1" {
+		t.Errorf("Expected foo 'bar:baz' and bar '1', but received: %v", ns.Mapping)
+	}
+}
+
+// TestParserFormatError tests that errors are prefixed with the basename of the
+// program, or a custom program name, and followed by the parser's usage.
+func TestParserFormatError(t *testing.T) {
+	p := NewParser("parser").Path(string(os.PathSeparator) + join(string(os.PathSeparator), "usr", "bin", "tool"))
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewArg("name", "name", "a name"),
+	)
+
+	_, _, err := p.Parse("--quiet", "bob")
+	if err == nil {
+		t.Fatal("An error was expected but did not occur")
+	}
+
+	expected := "tool: error: invalid option \"quiet\"\nusage: tool [-h] [-v] <name>"
+	if actual := p.FormatError(err); actual != expected {
+		t.Errorf("Expected formatted error:\n%s\nbut received:\n%s", expected, actual)
+	}
+
+	p.Prog("custom")
+	expected = "custom: error: invalid option \"quiet\"\nusage: custom [-h] [-v] <name>"
+	if actual := p.FormatError(err); actual != expected {
+		t.Errorf("Expected formatted error:\n%s\nbut received:\n%s", expected, actual)
+	}
+}
+
+// TestParserDeprecatedOption tests that a deprecated option still stores its
+// value, outputs a single warning however often it is used, and is hidden.
+func TestParserDeprecatedOption(t *testing.T) {
+	var warnings bytes.Buffer
+	p := NewParser("parser").SetWarningOutput(&warnings)
+	p.AddOptions(
+		NewOption("new-name", "name", "the name").Nargs("1").Action(Store),
+		NewOption("old-name", "name", "the name").Nargs("1").Action(Store).Deprecated("use --new-name"),
+	)
+
+	ns, _, err := p.Parse("--old-name", "a", "--old-name", "b")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("name") != "b" {
+		t.Errorf("Expected name 'b', but received: '%s'", ns.String("name"))
+	}
+
+	expected := "warning: --old-name is deprecated: use --new-name\n"
+	if warnings.String() != expected {
+		t.Errorf("Expected warning '%s', but received: '%s'", expected, warnings.String())
+	}
+
+	if strings.Contains(p.GetHelp(), "old-name") == true {
+		t.Error("Expected the deprecated option to be absent from the help text")
+	}
+}
+
+// TestParserOptionAlias tests that an option's aliases store to the same
+// destination and mark the option as set, while only its canonical names are
+// listed within the help text, and that deprecated aliases output a warning.
+func TestParserOptionAlias(t *testing.T) {
+	var warnings bytes.Buffer
+	p := NewParser("parser").SetWarningOutput(&warnings)
+	p.AddOptions(
+		NewOption("o output", "output", "output file").Nargs("1").Action(Store).Alias("out").DeprecatedAlias("", "outfile"),
+		NewFlag("q quiet", "quiet", "less output").DeprecatedAlias("use --quiet", "silent"),
+	)
+
+	for _, args := range [][]string{{"--output", "x"}, {"--out", "x"}, {"-o", "x"}, {"--outfile", "x"}} {
+		ns, _, err := p.Parse(args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred: %s", err.Error())
+		}
+		if ns.String("output") != "x" {
+			t.Errorf("Expected output 'x' for %v, but received: '%s'", args, ns.String("output"))
+		}
+		for _, name := range []string{"output", "out", "outfile"} {
+			if p.WasSet(name) == false {
+				t.Errorf("Expected %s to be set for %v", name, args)
+			}
+		}
+	}
+
+	if _, _, err := p.Parse("--silent", "--silent", "--outfile", "y"); err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	expected := "warning: --outfile is deprecated, use --output\n" +
+		"warning: --silent is deprecated: use --quiet\n" +
+		"warning: --outfile is deprecated, use --output\n"
+	if warnings.String() != expected {
+		t.Errorf("Expected warnings:\n%s\nbut received:\n%s", expected, warnings.String())
+	}
+
+	help := p.GetHelp()
+	if strings.Contains(help, "-o, --output OUTPUT") == false || strings.Contains(help, "-q, --[no-]quiet") == false {
+		t.Errorf("Expected the canonical names within the help text:\n%s", help)
+	}
+	for _, alias := range []string{"--out ", "--outfile", "--silent"} {
+		if strings.Contains(help, alias) == true {
+			t.Errorf("Expected the alias %s to be absent from the help text:\n%s", alias, help)
+		}
+	}
+}
+
+// TestParserHiddenOption tests that hidden options are parsed as usual, but are
+// absent from the help text and never suggested for mistyped options.
+func TestParserHiddenOption(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOptions(
+		NewFlag("v verbose", "verbose", "verbose output"),
+		NewFlag("internal-profile", "profile", "profile the program").Hidden(),
+	)
+
+	ns, _, err := p.Parse("--internal-profile")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+	if ns.String("profile") != "true" {
+		t.Errorf("Expected profile 'true', but received: '%s'", ns.String("profile"))
+	}
+
+	if help := p.GetHelp(); strings.Contains(help, "internal-profile") || strings.Contains(help, "verbose") == false {
+		t.Errorf("Expected the hidden option to be absent from the help text:\n%s", help)
+	}
+
+	_, _, err = p.Parse("--internal-profilr")
+	if expected := `invalid option "internal-profilr"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s', but received: '%v'", expected, err)
+	}
+}
+
+// TestParserOptionalValue tests that an option with an optional value stores its
+// attached value, stores its constant value when bare, and never consumes the
+// following argument.
+func TestParserOptionalValue(t *testing.T) {
+	p := NewParser("parser")
+	p.AddOption(NewOption("color", "color", "colorize output").Action(Store).OptionalValue().Const("auto").Default("never"))
+
+	var tests = []struct {
+		args     []string
+		expected string
+		rest     int
+	}{
+		{[]string{}, "never", 0},
+		{[]string{"--color"}, "auto", 0},
+		{[]string{"--color=always"}, "always", 0},
+		{[]string{"--color", "file.txt"}, "auto", 1},
+	}
+
+	for _, test := range tests {
+		ns, args, err := p.Parse(test.args...)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred for %v: %s", test.args, err.Error())
+		}
+		if ns.String("color") != test.expected {
+			t.Errorf("Expected color '%s' for %v, but received: '%s'", test.expected, test.args, ns.String("color"))
+		}
+		if len(args) != test.rest {
+			t.Errorf("Expected %d remaining arguments for %v, but received: %v", test.rest, test.args, args)
+		}
+	}
+
+	if usage := p.GetUsage(); strings.Contains(usage, "[--color[=COLOR]]") == false {
+		t.Errorf("Expected the usage to show the optional value, but received: '%s'", usage)
+	}
+}
+
+// TestParserSetOutput tests the SetOutput and SetErrorOutput methods to ensure
+// the help and versioning text, errors, and warnings are output to the provided
+// writers, including by commands.
+func TestParserSetOutput(t *testing.T) {
+	var output, errOutput bytes.Buffer
+	p := NewParser("program description").Prog("prog").Version("1.0").SetOutput(&output).SetErrorOutput(&errOutput)
+	p.AddOption(NewFlag("old", "old", "an old flag").Deprecated(""))
+	p.AddCommand("run", "run the program")
+
+	if _, _, err := p.Parse("--help"); err == nil {
+		t.Error("Expected an error showing the help text, but received none")
+	}
+	if strings.HasPrefix(output.String(), "usage: prog") == false {
+		t.Errorf("Expected the help text within the output, but received: '%s'", output.String())
+	}
+
+	output.Reset()
+	p.ShowVersion()
+	if output.String() != p.GetVersion()+"\n" {
+		t.Errorf("Expected the version text within the output, but received: '%s'", output.String())
+	}
+
+	output.Reset()
+	if _, _, err := p.Parse("run", "--help"); err == nil || strings.HasPrefix(output.String(), "usage: prog run") == false {
+		t.Errorf("Expected the command's help text within the output, but received: '%s'", output.String())
+	}
+
+	p.Parse("--old")
+	p.PrintError(InvalidCommandErr{name: "bogus"})
+	expected := "warning: --old is deprecated\nprog: error: "
+	if strings.HasPrefix(errOutput.String(), expected) == false {
+		t.Errorf("Expected error output beginning '%s', but received: '%s'", expected, errOutput.String())
 	}
 }
 
@@ -249,6 +2600,6 @@ func TestNewParser(t *testing.T) {
 	}
 
 	if p.UsageText != desc {
-		t.Error("The parser's usage text: '%s' does not match the expected description: '%s'", p.UsageText, desc)
+		t.Errorf("The parser's usage text: '%s' does not match the expected description: '%s'", p.UsageText, desc)
 	}
 }
@@ -0,0 +1,44 @@
+# bash completion for proj
+_proj_completion() {
+    local cur prev cmd word
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+        case "$word" in
+            build|clean)
+                cmd="$word"
+                break
+                ;;
+        esac
+    done
+
+    case "$cmd" in
+        build)
+            case "$prev" in
+                --arch)
+                    COMPREPLY=($(compgen -W "amd64 arm64" -- "$cur"))
+                    return 0
+                    ;;
+            esac
+            COMPREPLY=($(compgen -W "--help --release --arch" -- "$cur"))
+            ;;
+        clean)
+            COMPREPLY=($(compgen -W "--help" -- "$cur"))
+            ;;
+        "")
+            case "$prev" in
+                --color)
+                    COMPREPLY=($(compgen -W "auto always never" -- "$cur"))
+                    return 0
+                    ;;
+            esac
+            if [[ "$cur" != -* ]]; then
+                COMPREPLY=($(compgen -W "build clean" -- "$cur"))
+                return 0
+            fi
+            COMPREPLY=($(compgen -W "--help --verbose --color -o" -- "$cur"))
+            ;;
+    esac
+}
+complete -F _proj_completion proj
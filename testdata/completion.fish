@@ -0,0 +1,11 @@
+# fish completion for proj
+complete -c proj -n '__fish_use_subcommand' -f -a 'build' -d 'Build the project'
+complete -c proj -n '__fish_use_subcommand' -f -a 'clean' -d 'Remove build artifacts'
+complete -c proj -n '__fish_use_subcommand' -s h -l help -d 'Show program help'
+complete -c proj -n '__fish_use_subcommand' -s v -l verbose -d 'Enable verbose output'
+complete -c proj -n '__fish_use_subcommand' -l color -d 'When to use color' -r -f -a 'auto always never'
+complete -c proj -n '__fish_use_subcommand' -s o -d 'Output file' -r
+complete -c proj -n '__fish_seen_subcommand_from build' -s h -l help -d 'Show program help'
+complete -c proj -n '__fish_seen_subcommand_from build' -l release -d 'Build in release mode'
+complete -c proj -n '__fish_seen_subcommand_from build' -l arch -d 'Target architecture' -r -f -a 'amd64 arm64'
+complete -c proj -n '__fish_seen_subcommand_from clean' -s h -l help -d 'Show program help'
@@ -1,129 +1,535 @@
 package argparse
 
 import (
-	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/nsf/termbox-go"
 )
 
+// optionRegex matches arguments which represent one or more options. Following
+// its first letter, a name may contain digits and hyphens, such as the negated
+// name `--no-color`.
+var optionRegex = regexp.MustCompile(`^-{1,2}[a-zA-Z][a-zA-Z0-9-]*$`)
+
+// argNumRegex matches a fixed number of arguments expected by an option.
+var argNumRegex = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+// extractedOption represents a single option extracted from a slice of
+// arguments, along with any value which was attached to it, and the index of the
+// argument it was extracted from.
+type extractedOption struct {
+	name     string
+	value    string
+	hasValue bool
+	index    int
+	detached bool
+}
+
 // extractOptions will extract all options from the slice of arguments provided,
 // returning one slice of invididual options, and a slice for all other arguments
-// present.
-func extractOptions(allArgs ...string) (options, args []string) {
+// present. Long options using the `--option=value` syntax will have their value
+// attached to the extracted option. All arguments following a standalone `--`
+// are returned as arguments, without being interpreted as options, and a
+// trailing `--` is consumed without returning any arguments. Empty arguments and
+// a lone `-`, which conventionally names stdin, are returned as arguments.
+func extractOptions(allArgs ...string) (options []extractedOption, args []string) {
+	return extractValuedOptions(nil, "=", allArgs...)
+}
+
+// extractValuedOptions behaves like extractOptions, but consults the provided
+// set of option names which expect a value. Once such an option is found within
+// a cluster of short options, the remainder of the cluster is attached to it as
+// its value, while a lone short option can also be separated from its value by
+// `=`, such as `-o=file`. Otherwise, the argument immediately following such an
+// option is attached as its value, unless that argument is itself an option.
+// Long options are separated from an attached value by the first of the
+// separator characters.
+func extractValuedOptions(valued map[string]bool, separators string, allArgs ...string) (options []extractedOption, args []string) {
+	options, args, _ = extractCountedOptions(valued, nil, separators, allArgs...)
+	return options, args
+}
+
+// extractCountedOptions behaves like extractValuedOptions, but also consults the
+// provided numbers of values expected by options expecting more than one, such
+// as `--coords 1 2 3`. The arguments following the first value of such an option
+// are its further values, up to the number it expects, stopping early at any
+// option or `--` terminator. The further values are returned keyed by the index
+// of the option's argument, rather than returned as arguments.
+func extractCountedOptions(valued map[string]bool, counts map[string]int, separators string, allArgs ...string) (options []extractedOption, args []string, values map[int][]string) {
 	count := 0
 	max := len(allArgs)
+	values = make(map[int][]string)
 
 	for count < max {
 		a := allArgs[count]
 
-		// If we have option-escape string, assume the next arg is supposed
-		// to be normal text instead of potentially being a option.
-		if a == "--" && len(allArgs) > count+1 {
-			args = append(args, allArgs[count+1])
-			count = count + 2
-			continue
+		// If we have option-escape string, all following args are supposed
+		// to be normal text instead of potentially being options.
+		if a == "--" {
+			args = append(args, allArgs[count+1:]...)
+			break
 		}
 
 		// Using a option regex, check if we have a normal param or a option.
-		optionRegex := regexp.MustCompile(`^-{1,2}[a-zA-Z]+$`)
-		if !optionRegex.MatchString(a) {
+		found := splitOption(a, valued, separators)
+		if len(found) == 0 {
 			args = append(args, a)
 			count++
 			continue
 		}
+		for i := range found {
+			found[i].index = count
+		}
+		count++
+
+		// The argument following an option expecting a value is that option's
+		// value, including negative numbers such as `-5`.
+		last := &found[len(found)-1]
+		if last.hasValue == false && valued[last.name] == true && count < max {
+			if next := allArgs[count]; next != "--" && len(splitOption(next, valued, separators)) == 0 {
+				last.value = next
+				last.hasValue = true
+				last.detached = true
+				count++
+			}
+		}
+		for remaining := counts[last.name] - 1; last.hasValue == true && remaining > 0 && count < max; remaining-- {
+			next := allArgs[count]
+			if next == "--" || len(splitOption(next, valued, separators)) > 0 {
+				break
+			}
+			values[last.index] = append(values[last.index], next)
+			count++
+		}
+		options = append(options, found...)
+	}
+
+	return options, args, values
+}
+
+// splitOption returns the individual options represented by the provided
+// argument, or nil if the argument does not represent any options.
+func splitOption(a string, valued map[string]bool, separators string) []extractedOption {
+	if strings.HasPrefix(a, "--") && strings.ContainsAny(a, separators) {
+		// If we have a long option with an attached value, split it on the
+		// first separator; any remaining separators belong to the value.
+		index := strings.IndexAny(a[2:], separators)
+		if name := a[2 : 2+index]; optionRegex.MatchString("--" + name) {
+			_, size := utf8.DecodeRuneInString(a[2+index:])
+			return []extractedOption{{name: name, value: a[2+index+size:], hasValue: true}}
+		}
+	} else if len(a) > 1 && a[0] == '-' && a[1] != '-' {
+		// A single short option followed by `=`, such as `-o=file`, has the
+		// remainder attached as its value. Within a longer cluster, such as
+		// `-oo=file`, the `=` is part of the value of an option expecting one.
+		if _, ok := splitShortOptions(a[1:2], nil); ok == true && len(a) > 2 && a[2] == '=' {
+			return []extractedOption{{name: a[1:2], value: a[3:], hasValue: true}}
+		}
+
+		// If short-option, grab all letters as individual options.
+		if shortOptions, ok := splitShortOptions(a[1:], valued); ok == true {
+			return shortOptions
+		}
+	} else if optionRegex.MatchString(a) {
+		return []extractedOption{{name: a[2:]}}
+	}
+
+	return nil
+}
+
+// splitShortOptions splits a cluster of short option names, without its prefix,
+// into individual options. When an option expecting a value is encountered, the
+// remainder of the cluster becomes its value, even when it names other options,
+// as within `-fxv`, since it cannot be told apart from a value such as `-ofile`.
+// False is returned if the cluster does not represent short options.
+func splitShortOptions(cluster string, valued map[string]bool) ([]extractedOption, bool) {
+	var options []extractedOption
+
+	for i, c := range cluster {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return nil, false
+		}
+
+		name := string(c)
+		if valued[name] == true && i+1 < len(cluster) {
+			return append(options, extractedOption{name: name, value: cluster[i+1:], hasValue: true}), true
+		}
+		options = append(options, extractedOption{name: name})
+	}
+
+	return options, true
+}
+
+// maxResponseFileDepth is the number of response files which can be nested
+// within one another before expansion fails, preventing cycles.
+const maxResponseFileDepth = 10
+
+// expandResponseFiles replaces each `@file` argument with the arguments read
+// from the named file, expanding nested response files up to a limited depth.
+// Arguments beginning with `@@` are unescaped to begin with a literal `@`, and
+// arguments following a `--` terminator are returned unmodified.
+func expandResponseFiles(allArgs []string, depth int) ([]string, error) {
+	var expanded []string
+
+	for i, a := range allArgs {
+		if a == "--" {
+			return append(expanded, allArgs[i:]...), nil
+		} else if strings.HasPrefix(a, "@@") {
+			expanded = append(expanded, a[1:])
+			continue
+		} else if len(a) <= 1 || a[0] != '@' {
+			expanded = append(expanded, a)
+			continue
+		}
+
+		name := a[1:]
+		if depth >= maxResponseFileDepth {
+			return nil, ResponseFileErr{name, fmt.Errorf("exceeded %d nested response files", maxResponseFileDepth)}
+		}
+
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, ResponseFileErr{name, err}
+		}
+		fileArgs, err := splitResponseFile(string(content))
+		if err != nil {
+			return nil, ResponseFileErr{name, err}
+		}
+		fileArgs, err = expandResponseFiles(fileArgs, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, fileArgs...)
+	}
+
+	return expanded, nil
+}
+
+// splitResponseFile splits the content of a response file into arguments on
+// whitespace. Single or double quotes group text containing whitespace into a
+// single argument, and are removed. Backslashes are literal, so that paths such
+// as `C:\dir` remain intact. An error is returned for unclosed quotes.
+func splitResponseFile(content string) ([]string, error) {
+	return splitArgs(content, false)
+}
+
+// prefixedName returns the provided option name prefixed with `-` when it is a
+// short name, or with `--` when it is a long name.
+func prefixedName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// parseBool returns the boolean value represented by the provided string. Any
+// value accepted by strconv.ParseBool is accepted, as are `yes` and `no`.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("expected a boolean value such as true or false")
+	}
+	return b, nil
+}
+
+// byteSizeRegex matches a size, such as "10MB", "1.5 GiB", or "512", capturing
+// its number and its unit.
+var byteSizeRegex = regexp.MustCompile(`^([0-9]+)(\.[0-9]+)?\s*([a-zA-Z]*)$`)
+
+// byteUnits contains the number of bytes in each unit of size, keyed by the
+// lowercased unit. Units such as "MB" are decimal, while units such as "MiB" are
+// binary.
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// parseByteSize returns the number of bytes represented by the provided size,
+// which is a number followed by an optional unit, matched case-insensitively,
+// such as "10MB" for 10,000,000 bytes, or "10MiB" for 10,485,760 bytes. A size
+// without a unit is a number of bytes. A fractional size is rounded to the
+// nearest byte.
+func parseByteSize(value string) (int64, error) {
+	match := byteSizeRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("expected a size such as 10MB or 512KiB")
+	}
+
+	unit, ok := byteUnits[strings.ToLower(match[3])]
+	if ok == false {
+		return 0, fmt.Errorf("unknown unit \"%s\"", match[3])
+	}
+
+	whole, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || whole > math.MaxInt64/unit {
+		return 0, fmt.Errorf("size out of range")
+	}
+
+	size := whole * unit
+	if len(match[2]) > 0 {
+		fraction, _ := strconv.ParseFloat("0"+match[2], 64)
+		if size > math.MaxInt64-unit {
+			return 0, fmt.Errorf("size out of range")
+		}
+		size = size + int64(math.Round(fraction*float64(unit)))
+	}
+	return size, nil
+}
+
+// parsePercent returns the fraction represented by the provided percentage,
+// which is a number followed by an optional `%`, such as 0.75 for "75%" or "75".
+func parsePercent(value string) (float64, error) {
+	number := strings.TrimSuffix(strings.TrimSpace(value), "%")
+	percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || math.IsNaN(percent) || math.IsInf(percent, 0) {
+		return 0, fmt.Errorf("expected a percentage such as 75%%")
+	}
+	return percent / 100, nil
+}
 
-		// Okay, we must have a option. Which type?
-		isShort := true
-		if len(a) > 2 && a[:2] == "--" {
-			isShort = false
+// typedString returns the provided string converted to the provided kind, such
+// as an int for reflect.Int, or the string itself if it cannot be converted.
+func typedString(kind reflect.Kind, value string) interface{} {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u, err := strconv.ParseUint(value, 10, 0); err == nil {
+			return u
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
 		}
+	case reflect.Bool:
+		if b, err := parseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// levenshtein returns the minimum number of single-rune insertions, deletions,
+// and substitutions required to change one string into the other.
+func levenshtein(a, b string) int {
+	source := []rune(a)
+	target := []rune(b)
 
-		// If short-option, grab all letters individual options.
-		if isShort == true {
-			for _, c := range a[1:] {
-				options = append(options, string(c))
+	previous := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current := make([]int, len(target)+1)
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			current[j] = previous[j-1]
+			if source[i-1] != target[j-1] {
+				current[j]++
+			}
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
 			}
-		} else {
-			options = append(options, a[2:])
 		}
-		count++
+		previous = current
 	}
 
-	return options, args
+	return previous[len(target)]
 }
 
-// getScreenWidth returns the width of the screen the program is executed within.
-func getScreenWidth() int {
+// DefaultScreenWidth is the screen width used when stdout is not a terminal,
+// or when the actual width of the screen cannot be determined.
+var DefaultScreenWidth = 80
+
+// DefaultMaxHelpWidth is the width which help text is wrapped to at most by new
+// parsers, however wide the screen is detected to be.
+var DefaultMaxHelpWidth = 100
+
+// termboxMutex serializes access to termbox, which manipulates the state of the
+// terminal, so the screen width can be determined from multiple goroutines.
+var termboxMutex sync.Mutex
+
+// termboxWidth returns the width of the terminal as reported by termbox, which
+// can be replaced to avoid initializing termbox, such as within tests. Calls
+// are serialized by termboxMutex.
+var termboxWidth = func() (int, error) {
 	if err := termbox.Init(); err != nil {
-		panic(err) // TODO: This should really be made to return an error.
+		return 0, err
 	}
 	w, _ := termbox.Size()
 	termbox.Close()
 
-	return w
+	return w, nil
 }
 
-// join will join the provided strings by the specified delimiter. The delimiter
-// does not have to be limited to a single character; any string can be a delimiter.
-func join(delimiter string, args ...string) string {
-	var join bytes.Buffer
-	num := len(args)
+// screenWidthFunc determines the width of the screen when rendering help text,
+// which can be replaced to avoid initializing termbox, such as within tests.
+var screenWidthFunc = getScreenWidth
 
-	if num == 0 {
-		return ""
+// getScreenWidth returns the width of the screen the program is executed within.
+// A valid width specified by the COLUMNS environment variable is used when
+// present. When stdout is not a terminal, DefaultScreenWidth is returned. An
+// error is returned if the width of the screen cannot be determined. It is safe
+// to call getScreenWidth from multiple goroutines.
+func getScreenWidth() (int, error) {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns, nil
 	}
 
-	for index, val := range args {
-		join.WriteString(val)
-		if index < num-1 {
-			join.WriteString(delimiter)
-		}
+	if isTerminal(os.Stdout) == false {
+		return DefaultScreenWidth, nil
 	}
 
-	return join.String()
+	termboxMutex.Lock()
+	defer termboxMutex.Unlock()
+
+	return termboxWidth()
+}
+
+// isTerminal returns true if the provided file is a terminal, or otherwise false.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// join will join the provided strings by the specified delimiter. The delimiter
+// does not have to be limited to a single character; any string can be a delimiter.
+func join(delimiter string, args ...string) string {
+	return strings.Join(args, delimiter)
 }
 
 // spacer provides a string containing only space-characters of the
 // exact number specified.
 func spacer(length int) string {
-	count := 0
-	char := " "
-	var buff bytes.Buffer
-
-	for count < length {
-		buff.WriteString(char)
-		count++
+	if length <= 0 {
+		return ""
 	}
+	return strings.Repeat(" ", length)
+}
 
-	return buff.String()
+// wideRanges contains the ranges of East Asian wide & fullwidth characters,
+// and emoji, which occupy two columns when displayed in a terminal.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x3FFFD},
+}
+
+// textWidth returns the number of columns the provided string occupies when
+// displayed in a terminal. Each rune is counted as a single column, except for
+// wide characters which are counted as two, and ANSI escape sequences, such as
+// for colors, which occupy no columns.
+func textWidth(text string) int {
+	width := 0
+	escaped := false
+	for _, r := range text {
+		if r == '\x1b' {
+			escaped = true
+			continue
+		} else if escaped == true {
+			// An escape sequence ends with its first letter, such as `m`.
+			escaped = (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+			continue
+		}
+
+		width++
+		for _, wide := range wideRanges {
+			if r >= wide[0] && r <= wide[1] {
+				width++
+				break
+			}
+		}
+	}
+	return width
 }
 
 // wordWrap breaks the provided string down into an array of strings with
-// character-counts not exceeding the specified max length.
+// display widths not exceeding the specified max length. Newlines within the
+// string are preserved, with each line being wrapped independently; empty
+// lines are returned as empty strings.
 func wordWrap(text string, max int) []string {
 	var lines []string
-	var line []string
-
-	if len(text) <= max {
-		return []string{text}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, wrapLine(line, max)...)
 	}
+	return lines
+}
 
-	split := strings.Split(text, " ")
-	length := 0
+// wrapLine breaks a single line of text down into an array of strings with
+// display widths not exceeding the specified max length.
+func wrapLine(text string, max int) []string {
+	var lines []string
+	var line []string
 
-	if len(split) <= 1 {
-		return split
+	// Consecutive, leading, and trailing whitespace, including tabs, is
+	// collapsed, so that no line contains an empty word.
+	split := strings.Fields(text)
+	text = join(" ", split...)
+
+	if textWidth(text) <= max || len(split) <= 1 {
+		return []string{text}
 	}
 
+	// The width of a line is the sum of its words' widths, plus a single
+	// space between each pair of adjacent words.
+	width := 0
 	for _, word := range split {
-		if len(word)+length+len(line) > max {
+		wordWidth := textWidth(word)
+		if len(line) > 0 && width+1+wordWidth > max {
 			lines = append(lines, join(" ", line...))
 			line = []string{word}
-			length = len(word)
+			width = wordWidth
 		} else {
-			length = length + len(word)
+			if len(line) > 0 {
+				width++
+			}
+			width = width + wordWidth
 			line = append(line, word)
 		}
 	}
@@ -131,3 +537,60 @@ func wordWrap(text string, max int) []string {
 
 	return lines
 }
+
+// wordWrapIndent behaves like wordWrap, but reserves the specified indent at the
+// start of each line, so that the wrapped lines fit within the max length when
+// following a column of that width. Every line after the first is prefixed with
+// the indent.
+func wordWrapIndent(text string, max, indent int) []string {
+	lines := wordWrap(text, max-indent)
+	for i := 1; i < len(lines); i++ {
+		lines[i] = spacer(indent) + lines[i]
+	}
+	return lines
+}
+
+// WrapText reflows the provided text into lines with display widths not exceeding
+// the specified width, including the indent, and returns them joined by newlines.
+// Newlines within the text are preserved, as separate paragraphs. Every line is
+// prefixed with the indent, or when indentFirst is false, every line after the
+// first, such as for text following a label; empty lines remain empty.
+func WrapText(text string, width, indent int, indentFirst bool) string {
+	lines := wordWrap(text, width-indent)
+	for i, line := range lines {
+		if len(line) > 0 && (i > 0 || indentFirst == true) {
+			lines[i] = spacer(indent) + line
+		}
+	}
+	return join("\n", lines...)
+}
+
+// wordWrapHard behaves like wordWrap, but additionally breaks any words longer
+// than the specified max length across multiple lines. Words are only broken on
+// rune boundaries, so multi-byte characters are never split.
+func wordWrapHard(text string, max int) []string {
+	var lines []string
+
+	for _, line := range wordWrap(text, max) {
+		if max <= 0 || textWidth(line) <= max {
+			lines = append(lines, line)
+			continue
+		}
+
+		var chunk []rune
+		width := 0
+		for _, r := range line {
+			runeWidth := textWidth(string(r))
+			if width+runeWidth > max && len(chunk) > 0 {
+				lines = append(lines, string(chunk))
+				chunk = nil
+				width = 0
+			}
+			chunk = append(chunk, r)
+			width = width + runeWidth
+		}
+		lines = append(lines, string(chunk))
+	}
+
+	return lines
+}
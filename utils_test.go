@@ -1,8 +1,17 @@
 package argparse
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing" //import go package for testing related functionality
+	"time"
+	"unicode/utf8"
 )
 
 // TestExtractOptions_NoArgs tests to ensure that when no arguments are provided,
@@ -108,28 +117,503 @@ func TestExtractOptions(t *testing.T) {
 	}
 }
 
+// TestExtractOptions_Terminator tests to ensure that every argument following a
+// standalone `--` is extracted as a passive argument, including any further `--`.
+func TestExtractOptions_Terminator(t *testing.T) {
+	allArgs := []string{"-v", "input", "--", "--not-a-flag", "-x", "--", "--level=3"}
+
+	options, args := extractOptions(allArgs...)
+
+	if len(options) != 1 || options[0].name != "v" {
+		t.Errorf("Expected only option 'v' to be extracted, but received: %v", options)
+	}
+
+	expected := []string{"input", "--not-a-flag", "-x", "--", "--level=3"}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %q, but received: %q", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected arg '%s' at %d, but received: '%s'", expected[i], i, args[i])
+		}
+	}
+}
+
+// TestExtractOptions_LongNames tests to ensure that long options containing
+// digits and hyphens are extracted, while a standalone `--` remains the
+// terminator, and short options remain single letters.
+func TestExtractOptions_LongNames(t *testing.T) {
+	options, args := extractOptions("--log-level", "debug", "--max-retries=3", "--ipv6", "-4", "--", "--ipv4")
+	expected := []extractedOption{
+		{"log-level", "", false, 0, false},
+		{"max-retries", "3", true, 2, false},
+		{"ipv6", "", false, 3, false},
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf("Expected options %v, but received: %v", expected, options)
+	}
+	for i := range expected {
+		if options[i] != expected[i] {
+			t.Errorf("Expected option %v at %d, but received: %v", expected[i], i, options[i])
+		}
+	}
+
+	if len(args) != 3 || args[0] != "debug" || args[1] != "-4" || args[2] != "--ipv4" {
+		t.Errorf("Expected args [debug -4 --ipv4], but received: %q", args)
+	}
+}
+
+// TestExtractOptions_EmptyArgs tests to ensure that empty arguments and a lone
+// `-` are extracted as passive arguments, including an empty argument following
+// a `--`, and that a trailing `--` is consumed as the terminator.
+func TestExtractOptions_EmptyArgs(t *testing.T) {
+	var cases = []struct {
+		allArgs  []string
+		expected []string
+	}{
+		{[]string{""}, []string{""}},
+		{[]string{"", ""}, []string{"", ""}},
+		{[]string{"-"}, []string{"-"}},
+		{[]string{"--", ""}, []string{""}},
+		{[]string{"-v", "--"}, nil},
+		{[]string{"--"}, nil},
+	}
+
+	for _, c := range cases {
+		options, args := extractOptions(c.allArgs...)
+		if len(args) != len(c.expected) {
+			t.Errorf("Expected args %q for %q, but received: %q", c.expected, c.allArgs, args)
+			continue
+		}
+		for i := range c.expected {
+			if args[i] != c.expected[i] {
+				t.Errorf("Expected arg '%s' at %d for %q, but received: '%s'", c.expected[i], i, c.allArgs, args[i])
+			}
+		}
+		for _, option := range options {
+			if option.name != "v" {
+				t.Errorf("Expected no options other than 'v' for %q, but received: %v", c.allArgs, options)
+			}
+		}
+	}
+
+	options, _ := extractValuedOptions(map[string]bool{"o": true}, "=", "-o", "")
+	if len(options) != 1 || options[0] != (extractedOption{"o", "", true, 0, true}) {
+		t.Errorf("Expected option 'o' with an empty value, but received: %v", options)
+	}
+}
+
+// TestExtractOptions_AttachedValues tests to ensure that long options using the
+// `--option=value` syntax are extracted with their attached values, including
+// empty values, values containing spaces, and values containing `=`.
+func TestExtractOptions_AttachedValues(t *testing.T) {
+	allArgs := []string{"--output=file.txt", "--name=", "--greeting=hello world", "--filter=a=b", "--verbose"}
+	expected := []extractedOption{
+		{"output", "file.txt", true, 0, false},
+		{"name", "", true, 1, false},
+		{"greeting", "hello world", true, 2, false},
+		{"filter", "a=b", true, 3, false},
+		{"verbose", "", false, 4, false},
+	}
+
+	options, args := extractOptions(allArgs...)
+
+	if len(args) != 0 {
+		t.Error("No arguments should have been extracted")
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf(
+			"%d number of options expected, but only %d were extracted",
+			len(expected),
+			len(options),
+		)
+	}
+
+	for i, option := range options {
+		if option != expected[i] {
+			t.Errorf("Expected option: '%v' but received: '%v'", expected[i], option)
+		}
+	}
+}
+
+// TestExtractOptions_Separators tests to ensure that long options are split from
+// their values on the first of the configured separator characters only.
+func TestExtractOptions_Separators(t *testing.T) {
+	options, args := extractValuedOptions(nil, "=:", "--foo:bar:baz", "--out=a:b", "--path:c=d")
+
+	expected := []extractedOption{{"foo", "bar:baz", true, 0, false}, {"out", "a:b", true, 1, false}, {"path", "c=d", true, 2, false}}
+	if len(args) != 0 || len(options) != len(expected) {
+		t.Fatalf("Expected options %v and no args, but received: %v %v", expected, options, args)
+	}
+	for i := range expected {
+		if options[i] != expected[i] {
+			t.Errorf("Expected option %v at %d, but received: %v", expected[i], i, options[i])
+		}
+	}
+
+	options, args = extractOptions("--foo:bar")
+	if len(options) != 0 || len(args) != 1 {
+		t.Errorf("Expected `:` not to separate values by default, but received: %v %v", options, args)
+	}
+}
+
+// TestExtractValuedOptions tests to ensure that a short option expecting a value
+// will take the remainder of its cluster as its value, while clusters of other
+// short options are still extracted individually.
+func TestExtractValuedOptions(t *testing.T) {
+	valued := map[string]bool{"o": true}
+
+	options, args := extractValuedOptions(valued, "=", "-ofile.txt", "-xvf", "arg", "-vo", "out", "-o")
+	expected := []extractedOption{
+		{"o", "file.txt", true, 0, false},
+		{"x", "", false, 1, false},
+		{"v", "", false, 1, false},
+		{"f", "", false, 1, false},
+		{"v", "", false, 3, false},
+		{"o", "out", true, 3, true},
+		{"o", "", false, 5, false},
+	}
+
+	if len(args) != 1 || args[0] != "arg" {
+		t.Errorf("Expected arguments: '[arg]' but received: '%v'", args)
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf(
+			"%d number of options expected, but only %d were extracted",
+			len(expected),
+			len(options),
+		)
+	}
+
+	for i, option := range options {
+		if option != expected[i] {
+			t.Errorf("Expected option: '%v' but received: '%v'", expected[i], option)
+		}
+	}
+}
+
+// TestExtractValuedOptions_ShortEquals tests to ensure that a lone short option
+// followed by `=` has the remainder of the argument attached as its value, even
+// when empty, while within a cluster the first option expecting a value takes
+// the remainder of the cluster, including its `=`.
+func TestExtractValuedOptions_ShortEquals(t *testing.T) {
+	valued := map[string]bool{"o": true}
+
+	options, args := extractValuedOptions(valued, "=", "-o=file.txt", "-o=", "-oo=file.txt", "-v=x", "-o=a=b")
+	expected := []extractedOption{
+		{"o", "file.txt", true, 0, false},
+		{"o", "", true, 1, false},
+		{"o", "o=file.txt", true, 2, false},
+		{"v", "x", true, 3, false},
+		{"o", "a=b", true, 4, false},
+	}
+
+	if len(args) != 0 {
+		t.Errorf("No arguments should have been extracted, but received: '%v'", args)
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf(
+			"%d number of options expected, but only %d were extracted",
+			len(expected),
+			len(options),
+		)
+	}
+
+	for i, option := range options {
+		if option != expected[i] {
+			t.Errorf("Expected option: '%v' but received: '%v'", expected[i], option)
+		}
+	}
+}
+
+// TestExtractCountedOptions tests to ensure that an option expecting several
+// values takes the arguments following its first value, up to the number it
+// expects, stopping early at an option or a `--` terminator.
+func TestExtractCountedOptions(t *testing.T) {
+	valued := map[string]bool{"coords": true, "c": true}
+	counts := map[string]int{"coords": 3, "c": 3}
+
+	options, args, values := extractCountedOptions(valued, counts, "=", "--coords", "1", "2", "3", "4", "-c=5", "6", "-v", "7", "--coords", "8", "--", "9")
+	expected := []extractedOption{
+		{"coords", "1", true, 0, true},
+		{"c", "5", true, 5, false},
+		{"v", "", false, 7, false},
+		{"coords", "8", true, 9, true},
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf("%d number of options expected, but %d were extracted", len(expected), len(options))
+	}
+	for i, option := range options {
+		if option != expected[i] {
+			t.Errorf("Expected option: '%v' but received: '%v'", expected[i], option)
+		}
+	}
+
+	if expectedArgs := []string{"4", "7", "9"}; reflect.DeepEqual(args, expectedArgs) == false {
+		t.Errorf("Expected arguments: '%v' but received: '%v'", expectedArgs, args)
+	}
+	if expectedValues := map[int][]string{0: {"2", "3"}, 5: {"6"}}; reflect.DeepEqual(values, expectedValues) == false {
+		t.Errorf("Expected values: '%v' but received: '%v'", expectedValues, values)
+	}
+}
+
+// TestExtractValuedOptions_NegativeNumbers tests to ensure that negative numbers
+// are never extracted as options, and that they become the value of a preceding
+// option expecting a value. A bare `-` is expected to be an argument, unless it
+// follows an option expecting a value.
+func TestExtractValuedOptions_NegativeNumbers(t *testing.T) {
+	valued := map[string]bool{"n": true, "threshold": true}
+
+	for _, number := range []string{"-0", "-3.14", "-1e9"} {
+		options, args := extractValuedOptions(valued, "=", number)
+		if len(options) != 0 || len(args) != 1 {
+			t.Errorf("Expected '%s' to be extracted as an argument", number)
+		}
+
+		options, args = extractValuedOptions(valued, "=", "-n", number)
+		if len(args) != 0 || len(options) != 1 || options[0].value != number {
+			t.Errorf("Expected '%s' to be extracted as the value of -n", number)
+		}
+
+		options, args = extractValuedOptions(valued, "=", "--threshold", number)
+		if len(args) != 0 || len(options) != 1 || options[0].value != number {
+			t.Errorf("Expected '%s' to be extracted as the value of --threshold", number)
+		}
+	}
+
+	options, args := extractValuedOptions(valued, "=", "-", "-n", "-")
+	if len(options) != 1 || options[0].value != "-" {
+		t.Error("Expected '-' to be extracted as the value of -n")
+	}
+	if len(args) != 1 || args[0] != "-" {
+		t.Errorf("Expected arguments: '[-]' but received: '%v'", args)
+	}
+}
+
+// BenchmarkExtractOptions benchmarks extractOptions against a slice of 500
+// arguments containing a mixture of options and normal arguments.
+func BenchmarkExtractOptions(b *testing.B) {
+	var allArgs []string
+	for len(allArgs) < 500 {
+		allArgs = append(allArgs, "-abc", "--foobar", "--out=file.txt", "-n", "-5", "arg")
+	}
+	allArgs = allArgs[:500]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		extractOptions(allArgs...)
+	}
+}
+
+// TestSplitResponseFile tests to ensure the content of a response file is split
+// on whitespace, with quoted arguments kept intact.
+func TestSplitResponseFile(t *testing.T) {
+	args, err := splitResponseFile("--name \"John Smith\"\n-v\t'a \"quoted\" value'  \n\n last")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := []string{"--name", "John Smith", "-v", `a "quoted" value`, "last"}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %q, but received: %q", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected arg '%s' at %d, but received: '%s'", expected[i], i, args[i])
+		}
+	}
+
+	if _, err := splitResponseFile(`--name "John`); err == nil {
+		t.Error("An error was expected for an unclosed quote but did not occur")
+	}
+}
+
+// TestExpandResponseFiles tests to ensure `@file` arguments are replaced by the
+// arguments within the file, including nested files, while escaped arguments and
+// those following `--` are left as literal text.
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "argparse")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	nested := filepath.Join(dir, "nested.txt")
+	outer := filepath.Join(dir, "outer.txt")
+	cycle := filepath.Join(dir, "cycle.txt")
+	ioutil.WriteFile(nested, []byte("-v\n"), 0644)
+	ioutil.WriteFile(outer, []byte("--name 'John Smith' @"+nested+"\n"), 0644)
+	ioutil.WriteFile(cycle, []byte("@"+cycle), 0644)
+
+	args, err := expandResponseFiles([]string{"first", "@" + outer, "@@user", "@", "--", "@" + outer}, 0)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %s", err.Error())
+	}
+
+	expected := []string{"first", "--name", "John Smith", "-v", "@user", "@", "--", "@" + outer}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %q, but received: %q", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected arg '%s' at %d, but received: '%s'", expected[i], i, args[i])
+		}
+	}
+
+	if _, err := expandResponseFiles([]string{"@" + filepath.Join(dir, "missing.txt")}, 0); err == nil {
+		t.Error("An error was expected for a missing file but did not occur")
+	}
+
+	if _, err := expandResponseFiles([]string{"@" + cycle}, 0); err == nil {
+		t.Error("An error was expected for cyclic response files but did not occur")
+	}
+}
+
 // TestGetScreenWidth tests to ensure that a positive, non-zero integer value is returned
 // to represent the width of the current screen.
 func TestGetScreenWidth(t *testing.T) {
 	// I am not really sure the best way to test this.
 	// TODO: make a better test!
 
-	width := getScreenWidth()
-	if width <= 0 {
+	width, err := getScreenWidth()
+	if err == nil && width <= 0 {
 		t.Error("Retrieved screen width should be a positive, non-zero integer")
 	}
 }
 
+// TestGetScreenWidth_Columns tests to ensure that a valid width provided by the
+// COLUMNS environment variable is used, while invalid widths are ignored.
+func TestGetScreenWidth_Columns(t *testing.T) {
+	oldColumns, hadColumns := os.LookupEnv("COLUMNS")
+	defer func() {
+		if hadColumns {
+			os.Setenv("COLUMNS", oldColumns)
+		} else {
+			os.Unsetenv("COLUMNS")
+		}
+	}()
+
+	os.Setenv("COLUMNS", "123")
+	if width, err := getScreenWidth(); err != nil || width != 123 {
+		t.Errorf("Expected a screen width of 123, but received: %d", width)
+	}
+
+	for _, columns := range []string{"abc", "-5", "0"} {
+		os.Setenv("COLUMNS", columns)
+		if width, err := getScreenWidth(); err == nil && width <= 0 {
+			t.Errorf("Expected COLUMNS '%s' to be ignored, but received: %d", columns, width)
+		}
+	}
+}
+
+// TestGetScreenWidth_NotTerminal tests to ensure that DefaultScreenWidth is used
+// when stdout is not a terminal.
+func TestGetScreenWidth_NotTerminal(t *testing.T) {
+	oldColumns, hadColumns := os.LookupEnv("COLUMNS")
+	oldStdout := os.Stdout
+	oldDefault := DefaultScreenWidth
+	defer func() {
+		if hadColumns {
+			os.Setenv("COLUMNS", oldColumns)
+		}
+		os.Stdout = oldStdout
+		DefaultScreenWidth = oldDefault
+	}()
+
+	readFile, writeFile, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer readFile.Close()
+	defer writeFile.Close()
+
+	os.Unsetenv("COLUMNS")
+	os.Stdout = writeFile
+	DefaultScreenWidth = 42
+
+	if width, err := getScreenWidth(); err != nil || width != 42 {
+		t.Errorf("Expected a screen width of 42, but received: %d", width)
+	}
+}
+
+// TestGetScreenWidth_Concurrent tests to ensure that the screen width can be
+// determined, and help text rendered, from multiple goroutines at once, with
+// only one goroutine using termbox at a time. Stdout is replaced by a terminal
+// device, and termbox by a stub recording concurrent use.
+func TestGetScreenWidth_Concurrent(t *testing.T) {
+	oldColumns, hadColumns := os.LookupEnv("COLUMNS")
+	oldStdout := os.Stdout
+	oldWidth := termboxWidth
+	defer func() {
+		if hadColumns {
+			os.Setenv("COLUMNS", oldColumns)
+		}
+		os.Stdout = oldStdout
+		termboxWidth = oldWidth
+	}()
+
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer device.Close()
+	if isTerminal(device) == false {
+		t.Skipf("%s is not a character device", os.DevNull)
+	}
+
+	var active, overlapped int32
+	os.Unsetenv("COLUMNS")
+	os.Stdout = device
+	termboxWidth = func() (int, error) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return 80, nil
+	}
+
+	p := NewParser("parser")
+	p.AddOption(NewFlag("v verbose", "verbose", "verbose output"))
+	p.GetHelp()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if width, err := getScreenWidth(); err == nil && width <= 0 {
+				t.Errorf("Retrieved screen width should be a positive, non-zero integer, but received: %d", width)
+			}
+			p.GetHelp()
+		}()
+	}
+	wg.Wait()
+
+	if overlapped != 0 {
+		t.Error("Expected termbox to be used by one goroutine at a time")
+	}
+}
+
 // TestJoin tests to ensure that a variety of string slices can be joined in the
 // correct, expected manner.
 func TestJoin(t *testing.T) {
 	testStrings := [][]string{
+		[]string{},
+		[]string{"one"},
 		[]string{"one", "two"},
 		[]string{""},
 		[]string{"three", "four", "five"},
 	}
 
 	expectedStrings := []string{
+		"",
+		"one",
 		"one two",
 		"",
 		"three four five",
@@ -139,7 +623,7 @@ func TestJoin(t *testing.T) {
 		actual := join(" ", test...)
 		expected := expectedStrings[i]
 
-		if actual != expected {
+		if actual != expected || actual != bufferJoin(" ", test...) {
 			t.Errorf(
 				"Expected: '%s' but received: '%s'",
 				expected,
@@ -159,6 +643,155 @@ func TestJoin(t *testing.T) {
 	}
 }
 
+// bufferJoin is the previous, buffer-based implementation of join, retained to
+// benchmark against the current implementation.
+func bufferJoin(delimiter string, args ...string) string {
+	var join bytes.Buffer
+	num := len(args)
+
+	if num == 0 {
+		return ""
+	}
+
+	for index, val := range args {
+		join.WriteString(val)
+		if index < num-1 {
+			join.WriteString(delimiter)
+		}
+	}
+
+	return join.String()
+}
+
+// BenchmarkJoin benchmarks join against a large slice of strings.
+func BenchmarkJoin(b *testing.B) {
+	args := strings.Split(strings.Repeat("word ", 1000), " ")
+	for i := 0; i < b.N; i++ {
+		join(" ", args...)
+	}
+}
+
+// BenchmarkJoin_Buffer benchmarks the previous implementation of join against
+// a large slice of strings.
+func BenchmarkJoin_Buffer(b *testing.B) {
+	args := strings.Split(strings.Repeat("word ", 1000), " ")
+	for i := 0; i < b.N; i++ {
+		bufferJoin(" ", args...)
+	}
+}
+
+// TestLevenshtein tests to ensure the edit distance between strings is counted
+// in runes, rather than bytes.
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"verbose", "verbose", 0},
+		{"verbsoe", "verbose", 2},
+		{"colour", "color", 1},
+		{"kitten", "sitting", 3},
+		{"crème", "creme", 1},
+		{"日本語", "日本", 1},
+	}
+
+	for _, test := range tests {
+		if actual := levenshtein(test.a, test.b); actual != test.expected {
+			t.Errorf("Expected distance %d between '%s' and '%s' but received: %d", test.expected, test.a, test.b, actual)
+		}
+	}
+}
+
+// TestParseByteSize tests to ensure that sizes are converted to bytes for each
+// decimal and binary unit, regardless of case, and that malformed sizes, unknown
+// units, and sizes too large to be represented are rejected.
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		err      string
+	}{
+		{"512", 512, ""},
+		{"512B", 512, ""},
+		{"10KB", 10000, ""},
+		{"10MB", 10000000, ""},
+		{"2GB", 2000000000, ""},
+		{"1TB", 1000000000000, ""},
+		{"1PB", 1000000000000000, ""},
+		{"10KiB", 10240, ""},
+		{"10MiB", 10485760, ""},
+		{"2GiB", 2147483648, ""},
+		{"1TiB", 1099511627776, ""},
+		{"1PiB", 1125899906842624, ""},
+		{"10mb", 10000000, ""},
+		{"10Mb", 10000000, ""},
+		{"10mib", 10485760, ""},
+		{"10MIB", 10485760, ""},
+		{"1.5KiB", 1536, ""},
+		{"1.5 GB", 1500000000, ""},
+		{" 64kb ", 64000, ""},
+		{"", 0, "expected a size such as 10MB or 512KiB"},
+		{"MB", 0, "expected a size such as 10MB or 512KiB"},
+		{"-10MB", 0, "expected a size such as 10MB or 512KiB"},
+		{"1.MB", 0, "expected a size such as 10MB or 512KiB"},
+		{"10 M B", 0, "expected a size such as 10MB or 512KiB"},
+		{"10XB", 0, `unknown unit "XB"`},
+		{"10m", 0, `unknown unit "m"`},
+		{"9223372036854775808", 0, "size out of range"},
+		{"10000PB", 0, "size out of range"},
+	}
+
+	for _, test := range tests {
+		size, err := parseByteSize(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Expected error '%s' for '%s' but received: '%v'", test.err, test.value, err)
+			}
+			continue
+		}
+		if err != nil || size != test.expected {
+			t.Errorf("Expected %d bytes for '%s' but received: %d (%v)", test.expected, test.value, size, err)
+		}
+	}
+}
+
+// TestParsePercent tests to ensure that percentages are converted to fractions,
+// with or without a trailing %, and that malformed percentages are rejected.
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+		valid    bool
+	}{
+		{"75%", 0.75, true},
+		{"75", 0.75, true},
+		{"12.5%", 0.125, true},
+		{"0%", 0, true},
+		{"150%", 1.5, true},
+		{" 50 % ", 0.5, true},
+		{"", 0, false},
+		{"%", 0, false},
+		{"75%%", 0, false},
+		{"seventy%", 0, false},
+		{"NaN%", 0, false},
+	}
+
+	for _, test := range tests {
+		percent, err := parsePercent(test.value)
+		if test.valid == false {
+			if err == nil || err.Error() != "expected a percentage such as 75%" {
+				t.Errorf("Expected an error for '%s' but received: '%v'", test.value, err)
+			}
+			continue
+		}
+		if err != nil || percent != test.expected {
+			t.Errorf("Expected %v for '%s' but received: %v (%v)", test.expected, test.value, percent, err)
+		}
+	}
+}
+
 // TestSpacer tests to make sure the proper length strings are returned, as expected.
 func TestSpacer(t *testing.T) {
 	intTests := []int{-1000, -100, -10, -1, 0, 1, 10, 100, 1000}
@@ -192,3 +825,222 @@ func TestWordWrap(t *testing.T) {
 		t.Error("wordWrap did not return a slice of length 3")
 	}
 }
+
+// TestWrapText tests that text is reflowed into lines which fit within the width
+// including their indent, which is applied to every line, or to every line after
+// the first when indentFirst is false.
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text        string
+		width       int
+		indent      int
+		indentFirst bool
+		expected    string
+	}{
+		{"one two three", 13, 0, true, "one two three"},
+		{"one two three", 12, 0, true, "one two\nthree"},
+		{"one two three", 11, 4, true, "    one two\n    three"},
+		{"one two three", 10, 4, true, "    one\n    two\n    three"},
+		{"one two three", 10, 4, false, "one\n    two\n    three"},
+		{"one two\n\nthree", 20, 2, true, "  one two\n\n  three"},
+		{"", 10, 2, true, ""},
+	}
+
+	for _, test := range tests {
+		actual := WrapText(test.text, test.width, test.indent, test.indentFirst)
+		if actual != test.expected {
+			t.Errorf("Expected WrapText(%q, %d, %d, %t) to return %q, but received: %q", test.text, test.width, test.indent, test.indentFirst, test.expected, actual)
+		}
+	}
+}
+
+// TestTextWidth tests to ensure that multi-byte characters are counted as a single
+// column, while wide characters are counted as two columns.
+func TestTextWidth(t *testing.T) {
+	tests := map[string]int{
+		"":         0,
+		"hello":    5,
+		"crème":    5,
+		"日本語":      6,
+		"🎉":        2,
+		"abc 日本 é": 10,
+	}
+
+	for text, expected := range tests {
+		if actual := textWidth(text); actual != expected {
+			t.Errorf("Expected width %d for '%s' but received: %d", expected, text, actual)
+		}
+	}
+}
+
+// TestTextWidth_Escapes tests to ensure ANSI escape sequences, such as those of
+// colorized help text, occupy no columns.
+func TestTextWidth_Escapes(t *testing.T) {
+	tests := map[string]int{
+		"\x1b[1mbold\x1b[0m":   4,
+		"\x1b[1;36m日本\x1b[0m:": 5,
+	}
+
+	for text, expected := range tests {
+		if actual := textWidth(text); actual != expected {
+			t.Errorf("Expected width %d for %q but received: %d", expected, text, actual)
+		}
+	}
+}
+
+// TestWordWrap_MultiByte tests to ensure strings containing multi-byte and wide
+// characters are wrapped according to their display width rather than bytes.
+func TestWordWrap_MultiByte(t *testing.T) {
+	tests := []struct {
+		text  string
+		max   int
+		lines int
+	}{
+		{"ééé ééé", 7, 1},
+		{"crème brûlée", 12, 1},
+		{"🎉🎉 🎉🎉", 9, 1},
+		{"🎉🎉 🎉🎉", 8, 2},
+		{"日本語 日本語", 13, 1},
+		{"日本語 日本語", 12, 2},
+		{"abc 日本", 8, 1},
+		{"abc 日本", 7, 2},
+	}
+
+	for _, test := range tests {
+		if lines := wordWrap(test.text, test.max); len(lines) != test.lines {
+			t.Errorf(
+				"Expected '%s' wrapped to %d to have %d lines, but received: %v",
+				test.text,
+				test.max,
+				test.lines,
+				lines,
+			)
+		}
+	}
+}
+
+// TestWordWrap_Spaces tests to ensure that consecutive, leading, and trailing
+// spaces are collapsed, without producing empty words or lines.
+func TestWordWrap_Spaces(t *testing.T) {
+	tests := []struct {
+		text     string
+		max      int
+		expected []string
+	}{
+		{"hello   world  ", 80, []string{"hello world"}},
+		{"  hello   world  ", 6, []string{"hello", "world"}},
+		{"   ", 80, []string{""}},
+		{"\thello\t\tworld\t", 80, []string{"hello world"}},
+		{"one \t two\t  three \tfour", 9, []string{"one two", "three", "four"}},
+		{"\tfirst  \t line\n\t\tsecond\t line", 80, []string{"first line", "second line"}},
+		{"a\r\nb", 80, []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		lines := wordWrap(test.text, test.max)
+		if len(lines) != len(test.expected) {
+			t.Errorf("Expected '%s' wrapped to %d as %q, but received: %q", test.text, test.max, test.expected, lines)
+			continue
+		}
+		for i, line := range lines {
+			if line != test.expected[i] {
+				t.Errorf("Expected '%s' wrapped to %d as %q, but received: %q", test.text, test.max, test.expected, lines)
+				break
+			}
+		}
+	}
+}
+
+// TestWordWrap_Newlines tests to ensure that explicit newlines and empty lines
+// are preserved when strings are wrapped.
+func TestWordWrap_Newlines(t *testing.T) {
+	text := "first paragraph which wraps\n\nsecond paragraph\nthird"
+	expected := []string{"first paragraph", "which wraps", "", "second paragraph", "third"}
+
+	lines := wordWrap(text, 16)
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected lines: '%v' but received: '%v'", expected, lines)
+	}
+
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected line: '%s' but received: '%s'", expected[i], line)
+		}
+	}
+}
+
+// TestWordWrapIndent tests to ensure continuation lines are indented, and that
+// every line, including the indent, fits within the max length.
+func TestWordWrapIndent(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	max := 20
+	indent := 6
+
+	lines := wordWrapIndent(text, max, indent)
+	if len(lines) < 2 {
+		t.Fatalf("Expected multiple lines, but received: %q", lines)
+	}
+
+	for i, line := range lines {
+		width := textWidth(line)
+		if i == 0 {
+			width = width + indent
+		} else if strings.HasPrefix(line, spacer(indent)) == false || line[indent] == ' ' {
+			t.Errorf("Expected line %d to be indented by %d spaces: '%s'", i, indent, line)
+		}
+		if width > max {
+			t.Errorf("Line %d exceeds max width %d: '%s'", i, max, line)
+		}
+	}
+
+	words := strings.Fields(join(" ", lines...))
+	if join(" ", words...) != text {
+		t.Errorf("Expected wrapped text to contain '%s', but received: %q", text, lines)
+	}
+}
+
+// TestWordWrapHard tests to ensure that words longer than the max length are
+// broken across multiple lines without splitting multi-byte characters.
+func TestWordWrapHard(t *testing.T) {
+	word := strings.Repeat("abcdefghij", 12)
+	lines := wordWrapHard("see "+word, 40)
+
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, but received: %v", lines)
+	}
+	if lines[0] != "see" {
+		t.Errorf("Expected line: 'see' but received: '%s'", lines[0])
+	}
+	for _, line := range lines[1:] {
+		if len(line) != 40 {
+			t.Errorf("Expected line of length 40, but received: '%s'", line)
+		}
+	}
+
+	lines = wordWrapHard(strings.Repeat("é", 50), 20)
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, but received: %v", lines)
+	}
+	for i, expected := range []int{20, 20, 10} {
+		if !utf8.ValidString(lines[i]) || utf8.RuneCountInString(lines[i]) != expected {
+			t.Errorf("Expected line of %d runes, but received: '%s'", expected, lines[i])
+		}
+	}
+}
+
+// TestWordWrap_ExactWidth tests to ensure that a line filled to exactly the max
+// length is not broken prematurely.
+func TestWordWrap_ExactWidth(t *testing.T) {
+	lines := wordWrap("aaaa bbbb cccc dddd", 14)
+	expected := []string{"aaaa bbbb cccc", "dddd"}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected lines: '%v' but received: '%v'", expected, lines)
+	}
+
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected line: '%s' but received: '%s'", expected[i], line)
+		}
+	}
+}